@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/scan"
+	"github.com/adnaneAkk/envdoc/internal/secrets"
+	"github.com/adnaneAkk/envdoc/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	secretsBaseline string
+	forceBaseline   bool
+)
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Accept the secrets already committed so scans only fail on new ones",
+	Long:  `Keep a baseline of secrets that have been looked at and accepted, for adopting secrets scan on a repo that already has some. Only a salted hash of each value is stored, never the value`,
+}
+
+var baselineCreateCmd = &cobra.Command{
+	Use:   "create [file or directory...]",
+	Short: "Accept every secret secrets scan finds now",
+	Args:  cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBaselineCreate(args, forceBaseline)
+	},
+}
+
+var baselineUpdateCmd = &cobra.Command{
+	Use:   "update [file or directory...]",
+	Short: "Accept new and changed secrets, drop the ones that are gone",
+	Args:  cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBaselineUpdate(args)
+	},
+}
+
+var baselineAuditCmd = &cobra.Command{
+	Use:   "audit [file or directory...]",
+	Short: "Compare the baseline with the secrets found now",
+	Long:  `List every accepted secret with whether it's still there unchanged, has changed or is gone, and every secret that was never accepted. Exits with 1 when anything is new or changed`,
+	Args:  cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBaselineAudit(args)
+	},
+}
+
+func init() {
+	baselineCmd.PersistentFlags().StringVar(&secretsBaseline, "baseline", "", "Baseline file (default "+secrets.BaselineFile+" next to .envdoc.yaml)")
+	baselineCreateCmd.Flags().BoolVar(&forceBaseline, "force", false, "Replace an existing baseline, salt included")
+	baselineCmd.AddCommand(baselineCreateCmd, baselineUpdateCmd, baselineAuditCmd)
+	secretsCmd.AddCommand(baselineCmd)
+}
+
+// defaultBaseline is the baseline the secrets commands use without --baseline
+func defaultBaseline() string {
+	if secretsBaseline != "" {
+		return secretsBaseline
+	}
+	if project != nil {
+		return filepath.Join(filepath.Dir(project.Path), secrets.BaselineFile)
+	}
+	return secrets.BaselineFile
+}
+
+// baselineRel names a file the way the baseline does: relative to the baseline itself, so it
+// doesn't matter where envdoc is run from
+func baselineRel(baselinePath string, file scan.File) string {
+	if file.Path == parser.Stdin {
+		return file.Rel
+	}
+	base, err := filepath.Abs(filepath.Dir(baselinePath))
+	if err != nil {
+		return file.Rel
+	}
+	path, err := filepath.Abs(file.Path)
+	if err != nil {
+		return file.Rel
+	}
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return file.Rel
+	}
+	return filepath.ToSlash(rel)
+}
+
+// currentSecrets scans paths and hashes what it finds with b's salt. scanned has every file
+// that was looked at, so entries for other files can be told apart from secrets that are gone
+func currentSecrets(b *secrets.Baseline, baselinePath string, paths []string) (current []secrets.BaselineEntry, scanned map[string]bool) {
+	files := sharedFiles(paths)
+	results := scan.Secrets(files, types.Config{Strict: strict, Dialect: dialect}, detector, nil, secretsJobs)
+
+	scanned = map[string]bool{}
+	current = []secrets.BaselineEntry{}
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Println(r.Err)
+			os.Exit(1)
+		}
+		file := baselineRel(baselinePath, r.File)
+		scanned[file] = true
+		for _, s := range r.Secrets {
+			current = append(current, b.Entry(file, s.Key, s.Line, s.Rule, s.Value))
+		}
+	}
+	return current, scanned
+}
+
+// inScope splits the accepted entries into the files that were scanned and the rest
+func inScope(entries []secrets.BaselineEntry, scanned map[string]bool) (in, out []secrets.BaselineEntry) {
+	for _, e := range entries {
+		if scanned[e.File] {
+			in = append(in, e)
+		} else {
+			out = append(out, e)
+		}
+	}
+	return in, out
+}
+
+func runBaselineCreate(paths []string, force bool) {
+	path := defaultBaseline()
+	if _, err := os.Stat(path); err == nil && !force {
+		fmt.Printf("%s already exists, use envdoc secrets baseline update or --force\n", path)
+		os.Exit(1)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	b, err := secrets.NewBaseline()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	b.Entries, _ = currentSecrets(b, path, paths)
+	if err := b.Save(path); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	for _, e := range b.Entries {
+		fmt.Printf("  + %s:%d %s (%s)\n", e.File, e.Line, e.Key, e.Rule)
+	}
+	fmt.Printf("\n✓ Baseline written to %s (%d secret(s) accepted)\n", path, len(b.Entries))
+}
+
+func runBaselineUpdate(paths []string) {
+	path := defaultBaseline()
+	b, err := secrets.LoadBaseline(path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	current, scanned := currentSecrets(b, path, paths)
+	in, out := inScope(b.Entries, scanned)
+	diff := secrets.CompareBaseline(in, current)
+
+	// entries for files that weren't scanned this time stay as they are
+	b.Entries = append(out, current...)
+	if err := b.Save(path); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	for _, e := range diff.Added {
+		fmt.Printf("  + %s:%d %s (%s)\n", e.File, e.Line, e.Key, e.Rule)
+	}
+	for _, e := range diff.Changed {
+		fmt.Printf("  ~ %s:%d %s (%s, value changed)\n", e.File, e.Line, e.Key, e.Rule)
+	}
+	for _, e := range diff.Gone {
+		fmt.Printf("  - %s %s (no longer found)\n", e.File, e.Key)
+	}
+	fmt.Printf("\n✓ Baseline %s updated: %d added, %d changed, %d removed, %d unchanged\n",
+		path, len(diff.Added), len(diff.Changed), len(diff.Gone), len(diff.Kept))
+}
+
+func runBaselineAudit(paths []string) {
+	path := defaultBaseline()
+	b, err := secrets.LoadBaseline(path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	current, scanned := currentSecrets(b, path, paths)
+	in, _ := inScope(b.Entries, scanned)
+	diff := secrets.CompareBaseline(in, current)
+
+	fmt.Printf("=== Baseline audit: %s ===\n", path)
+	for _, e := range diff.Kept {
+		fmt.Printf("  ✓ %s:%d %s (%s) accepted\n", e.File, e.Line, e.Key, e.Rule)
+	}
+	for _, e := range diff.Changed {
+		fmt.Printf("  ✗ %s:%d %s (%s) changed since it was accepted\n", e.File, e.Line, e.Key, e.Rule)
+	}
+	for _, e := range diff.Added {
+		fmt.Printf("  ✗ %s:%d %s (%s) not in the baseline\n", e.File, e.Line, e.Key, e.Rule)
+	}
+	for _, e := range diff.Gone {
+		fmt.Printf("  - %s %s no longer found, update drops it\n", e.File, e.Key)
+	}
+	fmt.Printf("\n%d accepted, %d changed, %d new, %d gone\n", len(diff.Kept), len(diff.Changed), len(diff.Added), len(diff.Gone))
+
+	if len(diff.Changed) > 0 || len(diff.Added) > 0 {
+		os.Exit(1)
+	}
+}
@@ -5,54 +5,59 @@ import (
 	"os"
 	"strings"
 
+	"github.com/adnaneAkk/envdoc/internal/compare"
 	"github.com/adnaneAkk/envdoc/internal/parser"
-	"github.com/adnaneAkk/envdoc/internal/secrets"
+	"github.com/adnaneAkk/envdoc/internal/report"
 	"github.com/adnaneAkk/envdoc/internal/types"
 	"github.com/spf13/cobra"
 )
 
 var (
-	envFile1 string
-	envFile2 string
+	envFile1        string
+	envFile2        string
+	showMatrix      bool
+	compareFormat   string
+	compareExitCode bool
 )
 
 var compareCmd = &cobra.Command{
 
-	Use:   "compare [.env file1 ] [.env file2]",
+	Use:   "compare [.env file1 ] [.env file2] [more files...]",
 	Short: "Compares the second .env file to the first one",
-	Long:  `Compares two env files and report them to see the differences between them`,
-	Args:  cobra.MaximumNArgs(2),
+	Long:  `Compares two env files and report them to see the differences between them. With three or more files (or --matrix) the result is a key × file matrix`,
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		var e1, e2 string
-		if len(args) >= 1 {
-			e1 = args[0]
-		} else {
-			e1 = envFile1
+		files, err := expandFiles(args)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
-
-		if len(args) == 2 {
-			e2 = args[1]
-		} else {
-			e2 = envFile2
+		// the flags fill in whatever the arguments didn't
+		if len(files) < 1 && envFile1 != "" {
+			files = append(files, envFile1)
+		}
+		if len(files) < 2 && envFile2 != "" {
+			files = append(files, envFile2)
 		}
 
-		if e1 == "" || e2 == "" {
+		if len(files) < 2 {
 			fmt.Println("Error: you must provide two env files (either as args or flags)")
 			cmd.Usage()
 			os.Exit(1)
 		}
 		unmask, _ := cmd.Flags().GetBool("unmask")
 		if unmask {
-			fmt.Fprint(os.Stderr, "⚠  This will expose sensitive values in output. Continue? [y/N]: ")
-			var response string
-			fmt.Scanln(&response)
-			response = strings.ToLower(strings.TrimSpace(response))
-			if response != "y" && response != "yes" {
-				fmt.Fprintln(os.Stderr, "Aborted.")
-				os.Exit(0)
-			}
+			confirmUnmask()
+		}
+		var differ bool
+		if len(files) > 2 || showMatrix {
+			differ = runMatrix(files, strict, unmask, compareFormat)
+		} else {
+			differ = runCompare(files[0], files[1], strict, unmask, compareFormat)
+		}
+		if differ && compareExitCode {
+			os.Exit(1)
 		}
-		runCompare(e1, e2, strict, unmask)
 	},
 }
 
@@ -60,116 +65,85 @@ func init() {
 	compareCmd.Flags().StringVar(&envFile1, "env1", "", "First env file")
 	compareCmd.Flags().StringVar(&envFile2, "env2", "", "Second env file")
 	compareCmd.Flags().Bool("unmask", false, "unmask sensitive values in output")
+	compareCmd.Flags().BoolVar(&showMatrix, "matrix", false, "Show a key × file matrix even for two files")
+	compareCmd.Flags().StringVar(&compareFormat, "format", "text", "Output format ("+strings.Join(compare.Formats, "|")+")")
+	compareCmd.Flags().BoolVar(&compareExitCode, "exit-code", false, "Exit with 1 when the files differ, 0 when they don't")
 
 	rootCmd.AddCommand(compareCmd)
 }
 
-func runCompare(envfile1, envfile2 string, strictMode, unmask bool) {
-	config := types.Config{
-		Strict: strictMode,
-		Unmask: unmask,
-	}
+func runCompare(envfile1, envfile2 string, strictMode, unmask bool, format string) bool {
+	names, maps := parseCompared([]string{envfile1, envfile2}, strictMode, format)
 
-	EnvMap1, File1erors, File1warnings, err := parser.ParseFile(envfile1, config)
-	if err != nil {
+	comparison := compare.Diff(names[0], names[1], maps[0], maps[1])
+	if !unmask {
+		comparison.Redact(detector)
+	}
+	if err := compare.WriteDiff(compareOutput(), comparison, format); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	EnvMap2, File2erors, File2warnings, err := parser.ParseFile(envfile2, config)
-	if err != nil {
+	return len(comparison.Differences) > 0
+}
+
+// runMatrix compares any number of files at once, one row per key that isn't the same everywhere
+func runMatrix(filenames []string, strictMode, unmask bool, format string) bool {
+	names, maps := parseCompared(filenames, strictMode, format)
+
+	matrix := compare.Build(names, maps)
+	if !unmask {
+		matrix.Redact(detector)
+	}
+	if err := compare.WriteMatrix(compareOutput(), matrix, format); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	return len(matrix.Rows) > 0
+}
 
-	if len(File1erors) > 0 || len(File1warnings) > 0 {
-		fmt.Printf("\n=== Parse Issues: %s ===\n", envfile1)
-		for _, e := range File1erors {
-			fmt.Printf("  ✗ Line %d [%s]: %s (Key: %s)\n", e.LineNum, e.IssueType, e.Message, e.KeyName)
-		}
-		for _, w := range File1warnings {
-			fmt.Printf("  ⚠ Line %d [%s]: %s (Key: %s)\n", w.LineNum, w.IssueType, w.Message, w.KeyName)
-		}
+// parseCompared parses every file and reports their issues, stdout is kept for whatever a
+// script is going to read: the parse report with --output-format, the comparison otherwise
+func parseCompared(filenames []string, strictMode bool, format string) ([]string, []types.EnvVarMap) {
+	config := types.Config{
+		Strict:     strictMode,
+		Expand:     !noExpand,
+		ProcessEnv: processEnv,
+		Dialect:    dialect,
 	}
 
-	// Parse issues for file2
-	if len(File2erors) > 0 || len(File2warnings) > 0 {
-		fmt.Printf("\n=== Parse Issues: %s ===\n", envfile2)
-		for _, e := range File2erors {
-			fmt.Printf("  ✗ Line %d [%s]: %s (Key: %s)\n", e.LineNum, e.IssueType, e.Message, e.KeyName)
-		}
-		for _, w := range File2warnings {
-			fmt.Printf("  ⚠ Line %d [%s]: %s (Key: %s)\n", w.LineNum, w.IssueType, w.Message, w.KeyName)
+	names := make([]string, len(filenames))
+	maps := make([]types.EnvVarMap, len(filenames))
+	reports := make([]types.Report, len(filenames))
+	for i, filename := range filenames {
+		content, err := parser.ReadInput(filename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
-	}
-
-	//this is going to be responsable for the difference reporting
-	var difference types.DiffMap
-
-	for key1, value1 := range EnvMap1 {
-		value2, exists := EnvMap2[key1]
-
-		if !exists {
-			difference = append(difference, types.Diff{
-				DiffType: "missing key",
-				Message:  fmt.Sprintf("file %s is missing the key %s file %s has (line %d)", envFile2, key1, envFile1, value1.LineNum),
-				KeyName:  key1,
-				Value1:   value1.Value,
-				Value2:   "",
-			},
-			)
-		} else if value2.Value == value1.Value {
-			continue
-		} else {
-			difference = append(difference, types.Diff{
-				DiffType: "difference in value",
-				Message:  fmt.Sprintf(" key %s has value %s in file %s (line %d) ,but %s in file %s (line %d)", key1, value1.Value, envFile1, value1.LineNum, value2.Value, envFile2, value2.LineNum),
-				KeyName:  key1,
-				Value1:   value1.Value,
-				Value2:   value2.Value,
-			},
-			)
+		envVarMap, errors, warnings, err := parser.Parse(strings.NewReader(content), config)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
+		names[i] = parser.DisplayName(filename)
+		maps[i] = envVarMap
+		reports[i] = types.Report{File: names[i], Source: content, Errors: errors, Warnings: warnings}
 	}
 
-	for key2, value2 := range EnvMap2 {
-		_, exists := EnvMap1[key2]
-		if !exists {
-			difference = append(difference, types.Diff{
-				DiffType: "missing key",
-				Message:  fmt.Sprintf("file %s is missing the key %s file %s has (line %d)", envFile1, key2, envFile2, value2.LineNum),
-				KeyName:  key2,
-				Value1:   "",
-				Value2:   value2.Value,
-			},
-			)
-		}
-	}
-	if len(difference) == 0 {
-		fmt.Println("\n✓ Files are identical")
+	if reportFormat != "text" {
+		writeReports(reports)
+	} else if format != "text" {
+		report.Text{}.Report(os.Stderr, reports)
 	} else {
-		fmt.Printf("\n=== Comparison: %s vs %s ===\n", envfile1, envfile2)
-		for _, d := range difference {
-			sensitive := secrets.IsSensitiveKey(d.KeyName) ||
-				secrets.IsSensitiveValue(d.Value1) ||
-				secrets.IsSensitiveValue(d.Value2)
-
-			value1, value2 := d.Value1, d.Value2
-			if sensitive && !config.Unmask {
-				value1, value2 = "[SENSITIVE]", "[SENSITIVE]"
-			}
-
-			switch d.DiffType {
-			case "missing key":
-				if d.Value1 != "" {
-					fmt.Printf("  - %-20s = %s (only in %s)\n", d.KeyName, value1, envfile1)
-				} else {
-					fmt.Printf("  + %-20s = %s (only in %s)\n", d.KeyName, value2, envfile2)
-				}
-			case "difference in value":
-				fmt.Printf("  ~ %-20s %q → %q\n", d.KeyName, value1, value2)
-			}
-		}
-		fmt.Printf("\n%d difference(s) found\n", len(difference))
+		report.Text{}.Report(os.Stdout, reports)
 	}
+	return names, maps
+}
 
+// compareOutput is where the comparison goes, stderr when stdout has the parse report
+func compareOutput() *os.File {
+	if reportFormat != "text" {
+		return os.Stderr
+	}
+	return os.Stdout
 }
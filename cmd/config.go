@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adnaneAkk/envdoc/internal/config"
+	"github.com/adnaneAkk/envdoc/internal/lint"
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/schema"
+	"github.com/adnaneAkk/envdoc/internal/secrets"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	configFile string
+	project    *config.Project // nil when there is no .envdoc.yaml
+	detector   = secrets.Default()
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Project config file (default: "+config.FileName+" in this or a parent directory)")
+	rootCmd.PersistentPreRun = loadProjectConfig
+}
+
+// loadProjectConfig reads .envdoc.yaml and fills in every setting the command line left alone
+func loadProjectConfig(cmd *cobra.Command, args []string) {
+	path := configFile
+	if path == "" {
+		found, err := config.Find(".")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		path = found
+	}
+	if path == "" {
+		return
+	}
+
+	p, err := config.Load(path)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	project = p
+
+	// flags always win over the file
+	flags := cmd.Flags()
+	if p.Strict != nil && !flags.Changed("strict") {
+		strict = *p.Strict
+	}
+	if p.Dialect != "" && !flags.Changed("dialect") {
+		dialect = p.Dialect
+	}
+	if p.Expand != nil && !flags.Changed("no-expand") {
+		noExpand = !*p.Expand
+	}
+	if p.ProcessEnv != nil && !flags.Changed("process-env") {
+		processEnv = *p.ProcessEnv
+	}
+	if p.OutputFormat != "" && !flags.Changed("output-format") {
+		reportFormat = p.OutputFormat
+	}
+	if p.Schema != "" && !validateCmd.Flags().Changed("schema") {
+		schemaFile = p.Schema
+	}
+	if len(p.Scan.Include) > 0 && !scanCmd.Flags().Changed("include") {
+		scanInclude = p.Scan.Include
+	}
+	if len(p.Scan.Exclude) > 0 && !scanCmd.Flags().Changed("exclude") {
+		scanExclude = p.Scan.Exclude
+	}
+	if len(p.Scan.Exclude) > 0 && !secretsScanCmd.Flags().Changed("exclude") {
+		secretsExclude = p.Scan.Exclude
+	}
+
+	if _, err := parser.LookupDialect(dialect); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	for rule, severity := range p.Rules {
+		// Load has already checked both
+		level, _ := lint.ParseSeverity(severity)
+		lint.SetSeverity(rule, level)
+	}
+	detector, _ = secrets.New(p.Secrets) // Load has already compiled it once
+	if len(p.Booleans) > 0 {
+		schema.SetBooleanValues(p.Booleans)
+	}
+}
+
+// defaultEnvFiles are the files checked when none are named on the command line
+func defaultEnvFiles() []string {
+	if project != nil && len(project.Files) > 0 {
+		return project.Files
+	}
+	return []string{".env"}
+}
+
+// defaultEnvFile is the first default file, for commands that work on one file at a time
+func defaultEnvFile() string {
+	return defaultEnvFiles()[0]
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fromDialect string
+	toDialect   string
+)
+
+var dialectDiffCmd = &cobra.Command{
+	Use:   "dialect-diff [.env file]",
+	Short: "Show keys that load differently under two dialects",
+	Long:  `Parse one .env file with the rules of two loaders (docker-compose, python-dotenv, node, systemd, shell) and report keys whose resolved values differ`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			envFile = args[0]
+		} else {
+			envFile = defaultEnvFile()
+		}
+		unmask, _ := cmd.Flags().GetBool("unmask")
+		if unmask {
+			confirmUnmask()
+		}
+		runDialectDiff(envFile, fromDialect, toDialect, unmask)
+	},
+}
+
+func init() {
+	dialectDiffCmd.Flags().StringVar(&fromDialect, "from", "docker-compose", "First dialect")
+	dialectDiffCmd.Flags().StringVar(&toDialect, "to", "python-dotenv", "Second dialect")
+	dialectDiffCmd.Flags().Bool("unmask", false, "unmask sensitive values in output")
+	rootCmd.AddCommand(dialectDiffCmd)
+}
+
+func runDialectDiff(filename, from, to string, unmask bool) {
+	parse := func(name string) types.EnvVarMap {
+		config := types.Config{
+			Expand:     !noExpand,
+			ProcessEnv: processEnv,
+			Dialect:    name,
+		}
+		envVarMap, _, _, err := parser.ParseFile(filename, config)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return envVarMap
+	}
+	fromMap := parse(from)
+	toMap := parse(to)
+
+	keys := make(map[string]bool, len(fromMap))
+	for key := range fromMap {
+		keys[key] = true
+	}
+	for key := range toMap {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	differences := 0
+	fmt.Printf("=== %s: %s vs %s ===\n", filename, from, to)
+	for _, key := range sorted {
+		fromVar, inFrom := fromMap[key]
+		toVar, inTo := toMap[key]
+		if inFrom && inTo && fromVar.Value == toVar.Value {
+			continue
+		}
+		differences++
+
+		value1, value2 := fromVar.Value, toVar.Value
+		sensitive := detector.IsRedacted(key, value1) || detector.IsRedacted(key, value2)
+		if sensitive && !unmask {
+			value1, value2 = "[SENSITIVE]", "[SENSITIVE]"
+		}
+
+		switch {
+		case !inTo:
+			fmt.Printf("  - %-20s = %q (only under %s, line %d)\n", key, value1, from, fromVar.LineNum)
+		case !inFrom:
+			fmt.Printf("  + %-20s = %q (only under %s, line %d)\n", key, value2, to, toVar.LineNum)
+		default:
+			fmt.Printf("  ~ %-20s %q → %q (line %d)\n", key, value1, value2, fromVar.LineNum)
+		}
+	}
+
+	if differences == 0 {
+		fmt.Printf("\n✓ Every key loads the same under %s and %s\n", from, to)
+	} else {
+		fmt.Printf("\n%d key(s) load differently\n", differences)
+	}
+}
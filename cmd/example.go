@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exampleFile  string
+	blankValues  bool
+	checkExample bool
+)
+
+var exampleCmd = &cobra.Command{
+	Use:   "example [.env file]",
+	Short: "Generate a safe .env.example from a .env file",
+	Long:  `Write a .env.example that keeps the keys, comments and grouping of a .env file with sensitive values replaced by placeholders`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			envFile = args[0]
+		} else {
+			envFile = defaultEnvFile()
+		}
+		if checkExample {
+			runExampleCheck(envFile, exampleFile, strict, blankValues)
+			return
+		}
+		runExampleGeneration(envFile, exampleFile, strict, blankValues)
+	},
+}
+
+func init() {
+	exampleCmd.Flags().StringVarP(&exampleFile, "output", "o", ".env.example", "Example file to write (or check with --check)")
+	exampleCmd.Flags().BoolVar(&blankValues, "blank", false, "Leave every value empty instead of keeping non-sensitive defaults")
+	exampleCmd.Flags().BoolVar(&checkExample, "check", false, "Fail if the example file differs from what would be generated")
+	rootCmd.AddCommand(exampleCmd)
+}
+
+func runExampleGeneration(filename, outFile string, strictMode, blank bool) {
+	config := types.Config{
+		Strict:  strictMode,
+		Dialect: dialect,
+	}
+
+	envVarMap, errors, warnings, err := parser.ParseFile(filename, config)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	printIssues(filename, errors, warnings)
+
+	example, _ := renderExample(filename, envVarMap, blank)
+
+	if err := os.WriteFile(outFile, []byte(example), 0644); err != nil {
+		log.Fatalf("Error writing to file: %v", err)
+	}
+	fmt.Printf("\n✓ Example written to %s (%d keys)\n", outFile, len(envVarMap))
+
+	if len(errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+// renderExample builds the example for a parsed .env file, with the line written for each key.
+// Values are split the way the dialect reads them, so a value is never turned into a comment
+func renderExample(filename string, envVarMap types.EnvVarMap, blank bool) (string, map[string]string) {
+	content, err := parser.ReadInput(filename)
+	if err != nil {
+		log.Fatalf("Error reading file: %v", err)
+	}
+	d, err := parser.LookupDialect(dialect)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// line numbers tell us which assignments ParseFile kept
+	keyByLine := make(map[int]string, len(envVarMap))
+	for key, envVar := range envVarMap {
+		keyByLine[envVar.LineNum] = key
+	}
+
+	var sb strings.Builder
+	lines := make(map[string]string, len(envVarMap))
+	pendingBlank := false
+	for _, node := range parser.ParseDocument(content, d).Nodes {
+		var out string
+		switch n := node.(type) {
+		case *parser.Blank:
+			// collapse runs of blank lines but keep the grouping they mark
+			pendingBlank = sb.Len() > 0
+			continue
+		case *parser.Comment:
+			out = strings.TrimRight(n.Text, " \t")
+		case *parser.Assignment:
+			key, ok := keyByLine[n.LineNum]
+			if !ok {
+				// invalid lines and duplicates don't belong in a template
+				continue
+			}
+			out = key + "=" + exampleValue(key, envVarMap[key].Value, n.Value, blank) + inlineComment(n.Trailing)
+			lines[key] = out
+		default:
+			continue
+		}
+
+		if pendingBlank {
+			sb.WriteString("\n")
+			pendingBlank = false
+		}
+		sb.WriteString(out + "\n")
+	}
+	return sb.String(), lines
+}
+
+// exampleValue picks what goes right of the '=': nothing, a placeholder, or the value exactly as the source wrote it
+func exampleValue(key, value, raw string, blank bool) string {
+	if blank || value == "" {
+		return ""
+	}
+	if detector.IsRedacted(key, value) {
+		return "your_" + strings.ToLower(key) + "_here"
+	}
+	return raw
+}
+
+// inlineComment is the # comment from what trails a value, with the spacing before it, and
+// nothing when there's no comment
+func inlineComment(trailing string) string {
+	i := strings.IndexByte(trailing, '#')
+	if i == -1 {
+		return ""
+	}
+	space := trailing[len(strings.TrimRight(trailing[:i], " \t")):i]
+	if space == "" {
+		space = " "
+	}
+	return space + strings.TrimRight(trailing[i:], " \t")
+}
+
+func runExampleCheck(filename, exampleFilename string, strictMode, blank bool) {
+	config := types.Config{
+		Strict:  strictMode,
+		Dialect: dialect,
+	}
+
+	envVarMap, _, _, err := parser.ParseFile(filename, config)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	exampleMap, _, _, err := parser.ParseFile(exampleFilename, config)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	content, err := parser.ReadInput(exampleFilename)
+	if err != nil {
+		log.Fatalf("Error reading file: %v", err)
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	// the example is checked against exactly what generating it again would write
+	want, wantLines := renderExample(filename, envVarMap, blank)
+	if content == want {
+		fmt.Printf("✓ %s is up to date with %s\n", exampleFilename, filename)
+		return
+	}
+
+	var missing, stale, changed []string
+	for key := range envVarMap {
+		if _, exists := exampleMap[key]; !exists {
+			missing = append(missing, key)
+		}
+	}
+	for key := range exampleMap {
+		if _, exists := envVarMap[key]; !exists {
+			stale = append(stale, key)
+		}
+	}
+	d, _ := parser.LookupDialect(dialect)
+	for _, a := range parser.ParseDocument(content, d).Assignments() {
+		key := strings.TrimSpace(a.Key)
+		wantLine, ok := wantLines[key]
+		if !ok || exampleMap[key].LineNum != a.LineNum {
+			continue
+		}
+		if strings.TrimRight(strings.TrimSuffix(a.Raw(), a.Newline), " \t") != wantLine {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	sort.Strings(changed)
+
+	fmt.Printf("=== %s has drifted from %s ===\n", exampleFilename, filename)
+	for _, key := range missing {
+		fmt.Printf("  + %-20s (line %d of %s, missing from %s)\n", key, envVarMap[key].LineNum, filename, exampleFilename)
+	}
+	for _, key := range stale {
+		fmt.Printf("  - %-20s (line %d of %s, no longer in %s)\n", key, exampleMap[key].LineNum, exampleFilename, filename)
+	}
+	// values stay out of the output, an out of date example may hold a real one
+	for _, key := range changed {
+		fmt.Printf("  ~ %-20s (line %d of %s, not what %s generates)\n", key, exampleMap[key].LineNum, exampleFilename, filename)
+	}
+	regenerate := fmt.Sprintf("envdoc example %s -o %s", filename, exampleFilename)
+	if blank {
+		regenerate += " --blank"
+	}
+	if len(missing)+len(stale)+len(changed) == 0 {
+		fmt.Printf("  comments, key order or blank lines differ from %s\n", filename)
+		fmt.Printf("\nrun `%s` to regenerate\n", regenerate)
+	} else {
+		fmt.Printf("\n%d key(s) out of sync, run `%s` to regenerate\n", len(missing)+len(stale)+len(changed), regenerate)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/diff"
+	"github.com/adnaneAkk/envdoc/internal/lint"
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/report"
+	"github.com/adnaneAkk/envdoc/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dryRun        bool
+	keepDuplicate string
+)
+
+var fixCmd = &cobra.Command{
+	Use:   "fix [.env file]",
+	Short: "Apply the suggested fixes for a .env file's issues",
+	Long:  `Fix what envdoc knows how to fix in place: lowercase keys in strict mode, duplicate keys, content after a closing quote and dangling backslashes. With - the file is read from standard input and the fixed version written to standard output`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			envFile = args[0]
+		} else {
+			envFile = defaultEnvFile()
+		}
+		if err := lint.SetKeepDuplicate(keepDuplicate); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		runFix(envFile, strict, dryRun)
+	},
+}
+
+func init() {
+	fixCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print a unified diff instead of writing the file")
+	fixCmd.Flags().StringVar(&keepDuplicate, "keep", lint.KeepFirst, "Which copy of a duplicate key to keep (first|last)")
+	rootCmd.AddCommand(fixCmd)
+}
+
+func runFix(filename string, strictMode, dryRun bool) {
+	config := types.Config{
+		Strict:  strictMode,
+		Dialect: dialect,
+	}
+
+	content, err := parser.ReadInput(filename)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	name := parser.DisplayName(filename)
+	_, errors, warnings, err := parser.Parse(strings.NewReader(content), config)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	fixed, applied := lint.Apply(content, append(errors, warnings...))
+	// a pipe has nowhere to write back to, and should come out whole even when nothing changed
+	if filename == parser.Stdin && !dryRun {
+		fmt.Print(fixed)
+		return
+	}
+	if len(applied) == 0 {
+		fmt.Printf("✓ Nothing to fix in %s\n", name)
+		return
+	}
+
+	if dryRun {
+		fmt.Print(diff.Unified(name, name, content, fixed))
+		return
+	}
+
+	if err := os.WriteFile(filename, []byte(fixed), 0644); err != nil {
+		log.Fatalf("Error writing to file: %v", err)
+	}
+	for _, issue := range applied {
+		fmt.Printf("  Line %d [%s]: %s (Key: %s)\n", issue.LineNum, report.Label(issue), issue.Fix.Description, issue.KeyName)
+	}
+	fmt.Printf("\n✓ Applied %d fix(es) to %s\n", len(applied), filename)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/adnaneAkk/envdoc/internal/diff"
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkFormat bool
+	showDiff    bool
+	sortKeys    bool
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [.env file]",
+	Short: "Rewrite a .env file in canonical form",
+	Long:  `Normalize spacing, quoting and blank lines in a .env file without changing any value, like gofmt for .env files. With - the file is read from standard input and written to standard output`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			envFile = args[0]
+		} else {
+			envFile = defaultEnvFile()
+		}
+		runFormat(envFile, checkFormat, showDiff, sortKeys)
+	},
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&checkFormat, "check", false, "Don't write anything, fail if the file isn't formatted")
+	fmtCmd.Flags().BoolVar(&showDiff, "diff", false, "Print a unified diff instead of writing the file")
+	fmtCmd.Flags().BoolVar(&sortKeys, "sort", false, "Sort keys inside each section between comment lines, across blank lines")
+	rootCmd.AddCommand(fmtCmd)
+}
+
+func runFormat(filename string, check, showDiff, sort bool) {
+	config := types.Config{
+		Expand:     !noExpand,
+		ProcessEnv: processEnv,
+		Dialect:    dialect,
+	}
+
+	content, err := parser.ReadInput(filename)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	name := parser.DisplayName(filename)
+
+	formatted, err := parser.Format(content, config, parser.FormatOptions{SortKeys: sort})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	changed := formatted != content
+
+	if showDiff {
+		fmt.Print(diff.Unified(name, name, content, formatted))
+	}
+	if check {
+		if changed {
+			fmt.Printf("%s is not formatted, run `envdoc fmt %s`\n", name, filename)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ %s is formatted\n", name)
+		return
+	}
+	if showDiff {
+		return
+	}
+	// a pipe has nowhere to write back to
+	if filename == parser.Stdin {
+		fmt.Print(formatted)
+		return
+	}
+
+	if !changed {
+		fmt.Printf("✓ %s is already formatted\n", filename)
+		return
+	}
+	if err := os.WriteFile(filename, []byte(formatted), 0644); err != nil {
+		log.Fatalf("Error writing to file: %v", err)
+	}
+	fmt.Printf("✓ Formatted %s\n", filename)
+}
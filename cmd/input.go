@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/parser"
+)
+
+// expandFiles turns the files named on the command line into the list to check: globs like
+// services/*/.env are expanded (the shell usually does this, but not in CI yaml or on Windows),
+// "-" stays as standard input and duplicates are dropped
+func expandFiles(args []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	for _, arg := range args {
+		matches := []string{arg}
+		if arg != parser.Stdin && strings.ContainsAny(arg, "*?[") {
+			var err error
+			matches, err = filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("bad pattern %s: %v", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", arg)
+			}
+		}
+		for _, file := range matches {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files, nil
+}
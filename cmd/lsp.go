@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adnaneAkk/envdoc/internal/lsp"
+	"github.com/adnaneAkk/envdoc/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var lspSchema string
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a language server for .env files over stdio",
+	Long:  `Speak the Language Server Protocol on stdin/stdout: diagnostics as you type, hover with types and schema notes, completion of schema keys and quick fixes`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runLSP(strict, lspSchema)
+	},
+}
+
+func init() {
+	lspCmd.Flags().StringVar(&lspSchema, "schema", "", "Schema file for hover and completion (default: schema.json or schema.yaml next to each file)")
+	rootCmd.AddCommand(lspCmd)
+}
+
+func runLSP(strictMode bool, schemaPath string) {
+	if schemaPath == "" && project != nil {
+		schemaPath = project.Schema
+	}
+	server := lsp.NewServer(os.Stdin, os.Stdout, lsp.Options{
+		Config: types.Config{
+			Strict:     strictMode,
+			Expand:     !noExpand,
+			ProcessEnv: processEnv,
+			Dialect:    dialect,
+		},
+		Schema:  schemaPath,
+		Secrets: detector,
+	})
+	if err := server.Run(); err != nil {
+		// stdout belongs to the protocol
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -3,74 +3,128 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/report"
 	"github.com/adnaneAkk/envdoc/internal/types"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	strict  bool
-	envFile string
+	strict       bool
+	envFile      string
+	noExpand     bool
+	processEnv   bool
+	dialect      string
+	reportFormat string
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "envdoc [.env file]",
+	Use:   "envdoc [.env files...]",
 	Short: "Parse and validate .env files",
-	Long:  `A fast and flexible .env file parser with schema generation and validation`,
-	Args:  cobra.MaximumNArgs(1),
+	Long:  `A fast and flexible .env file parser with schema generation and validation. Files can be globs, or - for standard input`,
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) > 0 {
-			envFile = args[0]
-		} else {
-			envFile = ".env"
+		if len(args) == 0 {
+			args = defaultEnvFiles()
 		}
-		runValidation(envFile, strict)
+		files, err := expandFiles(args)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		runValidation(files, strict)
 	},
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&strict, "strict", "s", false, "Enable strict mode")
+	rootCmd.PersistentFlags().BoolVar(&noExpand, "no-expand", false, "Keep ${VAR} references as written instead of expanding them")
+	rootCmd.PersistentFlags().BoolVar(&processEnv, "process-env", false, "Let ${VAR} references fall back to the process environment")
+	rootCmd.PersistentFlags().StringVar(&reportFormat, "output-format", "text", "Issue output format ("+strings.Join(report.Formats(), "|")+")")
+	rootCmd.PersistentFlags().StringVar(&dialect, "dialect", parser.DefaultDialect, "Loader rules to parse with ("+strings.Join(parser.DialectNames(), "|")+")")
 }
 
 func Execute() error {
 	return rootCmd.Execute()
 }
 
-func runValidation(filename string, strictMode bool) {
+func runValidation(filenames []string, strictMode bool) {
 	config := types.Config{
-		Strict: strictMode,
+		Strict:     strictMode,
+		Expand:     !noExpand,
+		ProcessEnv: processEnv,
+		Dialect:    dialect,
 	}
 
-	envVarMap, errors, warnings, err := parser.ParseFile(filename, config)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-	// Print errors
-	if len(errors) > 0 {
-		fmt.Printf("Errors: %d found\n", len(errors))
-		for _, e := range errors {
-			fmt.Printf("  Line %d [%s]: %s (Key: %s)\n", e.LineNum, e.IssueType, e.Message, e.KeyName)
+	var reports []types.Report
+	counts := make(map[string]int, len(filenames))
+	failed := false
+	for _, filename := range filenames {
+		content, err := parser.ReadInput(filename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
+		envVarMap, errors, warnings, err := parser.Parse(strings.NewReader(content), config)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		name := parser.DisplayName(filename)
+		reports = append(reports, types.Report{File: name, Source: content, Errors: errors, Warnings: warnings})
+		counts[name] = len(envVarMap)
+		failed = failed || len(errors) > 0
 	}
 
-	// Print warnings
-	if len(warnings) > 0 {
-		fmt.Printf("\nWarnings: %d found\n", len(warnings))
-		for _, w := range warnings {
-			fmt.Printf("  Line %d [%s]: %s (Key: %s)\n", w.LineNum, w.IssueType, w.Message, w.KeyName)
+	if reportFormat != "text" {
+		writeReports(reports)
+	} else {
+		report.Text{}.Report(os.Stdout, reports)
+
+		// Success message
+		for _, r := range reports {
+			if len(r.Errors) == 0 && len(r.Warnings) == 0 {
+				fmt.Printf("\n✓ %s is valid! Found %d environment variables.\n", r.File, counts[r.File])
+			}
 		}
 	}
 
-	// Success message
-	if len(errors) == 0 && len(warnings) == 0 {
-		fmt.Printf("\n✓ %s is valid! Found %d environment variables.\n", filename, len(envVarMap))
+	// Exit with error code if errors found in any file
+	if failed {
+		os.Exit(1)
 	}
+}
 
-	// Exit with error code if errors found
-	if len(errors) > 0 {
+func printIssues(filename string, errors, warnings []types.Issue) {
+	// already read once to parse it, so stdin comes from the cache
+	source, _ := parser.ReadInput(filename)
+	report.Text{}.Report(os.Stdout, []types.Report{{File: parser.DisplayName(filename), Source: source, Errors: errors, Warnings: warnings}})
+}
+
+// writeReports prints the reports in the --output-format picked by the user
+func writeReports(reports []types.Report) {
+	reporter, err := report.New(reportFormat)
+	if err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
+	if err := reporter.Report(os.Stdout, reports); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// confirmUnmask asks before sensitive values are printed, and exits if the answer isn't yes
+func confirmUnmask() {
+	fmt.Fprint(os.Stderr, "⚠  This will expose sensitive values in output. Continue? [y/N]: ")
+	var response string
+	fmt.Scanln(&response)
+	response = strings.ToLower(strings.TrimSpace(response))
+	if response != "y" && response != "yes" {
+		fmt.Fprintln(os.Stderr, "Aborted.")
+		os.Exit(0)
+	}
 }
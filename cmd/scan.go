@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"text/tabwriter"
+
+	"github.com/adnaneAkk/envdoc/internal/report"
+	"github.com/adnaneAkk/envdoc/internal/scan"
+	"github.com/adnaneAkk/envdoc/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanInclude []string
+	scanExclude []string
+	scanJobs    int
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [directory]",
+	Short: "Find and lint every .env file under a directory",
+	Long:  `Walk a directory tree, honouring .gitignore, and lint every .env* file found, several at a time. Issues are printed per file followed by a summary table`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := "."
+		if len(args) > 0 {
+			root = args[0]
+		}
+		runScan(root, strict)
+	},
+}
+
+func init() {
+	scanCmd.Flags().StringSliceVar(&scanInclude, "include", nil, "Only scan files matching these patterns (default .env, .env.*, *.env)")
+	scanCmd.Flags().StringSliceVar(&scanExclude, "exclude", nil, "Skip files and directories matching these patterns")
+	scanCmd.Flags().IntVarP(&scanJobs, "jobs", "j", runtime.NumCPU(), "How many files to lint at once")
+	rootCmd.AddCommand(scanCmd)
+}
+
+func runScan(root string, strictMode bool) {
+	config := types.Config{
+		Strict:     strictMode,
+		Expand:     !noExpand,
+		ProcessEnv: processEnv,
+		Dialect:    dialect,
+	}
+
+	files, err := scan.Find(root, scan.Options{Include: scanInclude, Exclude: scanExclude})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if len(files) == 0 {
+		fmt.Printf("No .env files found under %s\n", root)
+		return
+	}
+
+	results := scan.Lint(files, config, scanJobs)
+
+	reports := make([]types.Report, 0, len(results))
+	failed := false
+	for _, r := range results {
+		reports = append(reports, r.Report)
+		failed = failed || r.Err != nil || len(r.Report.Errors) > 0
+	}
+
+	if reportFormat != "text" {
+		writeReports(reports)
+	} else {
+		report.Text{}.Report(os.Stdout, reports)
+		printScanSummary(results)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func printScanSummary(results []scan.Result) {
+	errors, warnings := 0, 0
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tKIND\tKEYS\tERRORS\tWARNINGS")
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t%v\n", r.File.Rel, r.File.Kind, r.Err)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\n", r.File.Rel, r.File.Kind, r.Keys, len(r.Report.Errors), len(r.Report.Warnings))
+		errors += len(r.Report.Errors)
+		warnings += len(r.Report.Warnings)
+	}
+	tw.Flush()
+	fmt.Printf("\nScanned %d file(s): %d error(s), %d warning(s)\n", len(results), errors, warnings)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/report"
 	"github.com/adnaneAkk/envdoc/internal/schema"
 	"github.com/adnaneAkk/envdoc/internal/types"
 
@@ -19,28 +20,24 @@ var (
 )
 
 var schemaCmd = &cobra.Command{
-	Use:   "schema [.env file]",
-	Short: "Generate schema from .env file",
-	Long:  `Generate a JSON or YAML schema documenting all environment variables`,
-	Args:  cobra.MaximumNArgs(1),
+	Use:   "schema [.env files...]",
+	Short: "Generate schema from .env files",
+	Long:  `Generate a JSON or YAML schema documenting all environment variables. Several files (or globs) give one schema covering every key, - reads standard input`,
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) > 0 {
-			envFile = args[0]
-		} else {
-			envFile = ".env"
+		if len(args) == 0 {
+			args = []string{defaultEnvFile()}
+		}
+		files, err := expandFiles(args)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
 		unmask, _ := cmd.Flags().GetBool("unmask")
 		if unmask {
-			fmt.Fprint(os.Stderr, "⚠  This will expose sensitive values in output. Continue? [y/N]: ")
-			var response string
-			fmt.Scanln(&response)
-			response = strings.ToLower(strings.TrimSpace(response))
-			if response != "y" && response != "yes" {
-				fmt.Fprintln(os.Stderr, "Aborted.")
-				os.Exit(0)
-			}
+			confirmUnmask()
 		}
-		runSchemaGeneration(envFile, strict, unmask, outputFormat, outputFile)
+		runSchemaGeneration(files, strict, unmask, outputFormat, outputFile)
 	},
 }
 
@@ -51,35 +48,54 @@ func init() {
 	rootCmd.AddCommand(schemaCmd)
 }
 
-func runSchemaGeneration(filename string, strictMode, unmask bool, format string, outFile string) {
+func runSchemaGeneration(filenames []string, strictMode, unmask bool, format string, outFile string) {
 	config := types.Config{
-		Strict: strictMode,
-		Unmask: unmask,
+		Strict:     strictMode,
+		Unmask:     unmask,
+		Expand:     !noExpand,
+		ProcessEnv: processEnv,
+		Dialect:    dialect,
 	}
 
-	// Parse the file
-	envVarMap, errors, warnings, err := parser.ParseFile(filename, config)
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-	// Print errors/warnings
-	if len(errors) > 0 {
-		fmt.Printf("Errors: %d found\n", len(errors))
-		for _, e := range errors {
-			fmt.Printf("  Line %d [%s]: %s (Key: %s)\n", e.LineNum, e.IssueType, e.Message, e.KeyName)
+	// Parse every file, a key found in several keeps the first file's value as its example
+	envVarMap := types.EnvVarMap{}
+	var reports []types.Report
+	failed := false
+	for _, filename := range filenames {
+		content, err := parser.ReadInput(filename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
+		vars, errors, warnings, err := parser.Parse(strings.NewReader(content), config)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		for key, envVar := range vars {
+			if _, ok := envVarMap[key]; !ok {
+				envVarMap[key] = envVar
+			}
+		}
+		reports = append(reports, types.Report{File: parser.DisplayName(filename), Source: content, Errors: errors, Warnings: warnings})
+		failed = failed || len(errors) > 0
 	}
+	// Print errors/warnings
+	report.Text{}.Report(os.Stdout, reports)
 
-	if len(warnings) > 0 {
-		fmt.Printf("Warnings: %d found\n", len(warnings))
-		for _, w := range warnings {
-			fmt.Printf("  Line %d [%s]: %s (Key: %s)\n", w.LineNum, w.IssueType, w.Message, w.KeyName)
+	// Regenerating over an existing schema keeps the hand-written required flags and constraints
+	var existing types.Schema
+	if outFile != "" && format != "text" {
+		if _, err := os.Stat(outFile); err == nil {
+			existing, err = schema.Load(outFile)
+			if err != nil {
+				log.Fatalf("Error reading existing schema: %v", err)
+			}
 		}
 	}
 
 	// Generate schema
-	schemaData := schema.Generate(envVarMap, config)
+	schemaData := schema.Generate(envVarMap, existing, config, detector)
 
 	// Output based on format
 	output, err := schema.Output(schemaData, format)
@@ -97,8 +113,8 @@ func runSchemaGeneration(filename string, strictMode, unmask bool, format string
 		fmt.Println(output)
 	}
 
-	// Exit with error if errors found
-	if len(errors) > 0 {
+	// Exit with error if errors found in any file
+	if failed {
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/report"
+	"github.com/adnaneAkk/envdoc/internal/scan"
+	"github.com/adnaneAkk/envdoc/internal/secrets"
+	"github.com/adnaneAkk/envdoc/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	secretsFile    string
+	secretsFormat  string
+	secretsExclude []string
+	secretsJobs    int
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Inspect how envdoc detects secrets",
+}
+
+var secretsExplainCmd = &cobra.Command{
+	Use:   "explain KEY",
+	Short: "Show why a key is or isn't treated as sensitive",
+	Long:  `Run the secret detectors on one key and its value and list every reason it would be masked, most confident first. The value itself is never printed`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if secretsFile == "" {
+			secretsFile = defaultEnvFile()
+		}
+		runSecretsExplain(secretsFile, args[0], secretsFormat)
+	},
+}
+
+var secretsScanCmd = &cobra.Command{
+	Use:   "scan [file or directory...]",
+	Short: "Fail on real secrets committed in files meant to be shared",
+	Long:  `Check the files that get committed (.env.example, .env.template and the like, and schema files) for values that look like real secrets. Directories are walked honouring .gitignore and only shared files in them are checked, files named directly are always checked. Placeholders like changeme, xxx or <your-key> are fine. Findings are ED201 issues, so the command exits with 1 when there are any. Secrets accepted in a baseline (see secrets baseline) are let through as long as they don't change`,
+	Args:  cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSecretsScan(args, strict)
+	},
+}
+
+func init() {
+	secretsScanCmd.Flags().StringSliceVar(&secretsExclude, "exclude", nil, "Skip files and directories matching these patterns")
+	secretsScanCmd.Flags().IntVarP(&secretsJobs, "jobs", "j", runtime.NumCPU(), "How many files to check at once")
+	secretsScanCmd.Flags().StringVar(&secretsBaseline, "baseline", "", "Baseline of accepted secrets (default "+secrets.BaselineFile+" next to .envdoc.yaml, used when it exists)")
+	secretsCmd.AddCommand(secretsScanCmd)
+
+	secretsExplainCmd.Flags().StringVarP(&secretsFile, "file", "f", "", "The .env file to read the value from (default .env)")
+	secretsExplainCmd.Flags().StringVar(&secretsFormat, "format", "text", "Output format (text|json)")
+	secretsCmd.AddCommand(secretsExplainCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+// explanation is the json output of secrets explain
+type explanation struct {
+	Key          string            `json:"key"`
+	File         string            `json:"file"`
+	Line         int               `json:"line,omitempty"` // 0 when the file doesn't set the key
+	Sensitive    bool              `json:"sensitive"`
+	AllowedKey   bool              `json:"allowedKey,omitempty"`
+	AllowedValue bool              `json:"allowedValue,omitempty"`
+	Findings     []secrets.Finding `json:"findings"`
+	Explanation  string            `json:"explanation"`
+}
+
+func runSecretsExplain(filename, key, format string) {
+	config := types.Config{
+		Expand:     !noExpand,
+		ProcessEnv: processEnv,
+		Dialect:    dialect,
+	}
+
+	content, err := parser.ReadInput(filename)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	envVarMap, _, _, err := parser.Parse(strings.NewReader(content), config)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	envVar, found := envVarMap[key]
+	result := explanation{
+		Key:          key,
+		File:         parser.DisplayName(filename),
+		Line:         envVar.LineNum,
+		AllowedKey:   detector.AllowedKey(key),
+		AllowedValue: found && envVar.Value != "" && detector.AllowedValue(envVar.Value),
+		Findings:     detector.Findings(key, envVar.Value),
+	}
+	if result.Findings == nil {
+		result.Findings = []secrets.Finding{}
+	}
+	result.Sensitive = len(result.Findings) > 0
+
+	switch {
+	case result.AllowedKey:
+		result.Explanation = "not sensitive, the key is listed in secrets.allow-keys"
+	case result.Sensitive:
+		result.Explanation = fmt.Sprintf("sensitive, %s", result.Findings[0].Explanation)
+	case result.AllowedValue:
+		result.Explanation = "not sensitive, the value is listed in secrets.allow-values"
+	default:
+		result.Explanation = "not sensitive, no detector matched"
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(result)
+	case "text":
+		printExplanation(result, found)
+	default:
+		fmt.Printf("unknown format: %s (use text, json)\n", format)
+		os.Exit(1)
+	}
+}
+
+func printExplanation(e explanation, found bool) {
+	where := fmt.Sprintf("%s:%d", e.File, e.Line)
+	if !found {
+		where = "not set in " + e.File + ", only the name was checked"
+	}
+	fmt.Printf("%s (%s): %s\n", e.Key, where, e.Explanation)
+	if len(e.Findings) == 0 {
+		return
+	}
+
+	fmt.Println()
+	for _, f := range e.Findings {
+		fmt.Printf("  %.2f  %-15s %s\n", f.Confidence, f.Detector, f.Explanation)
+		if f.In == "key" {
+			// the key is safe to show, point at the word that matched
+			fmt.Printf("        %-15s %s\n", "", e.Key)
+			fmt.Printf("        %-15s %s%s\n", "", strings.Repeat(" ", f.Start), strings.Repeat("^", f.End-f.Start))
+		} else {
+			fmt.Printf("        %-15s bytes %d-%d of the value\n", "", f.Start+1, f.End)
+		}
+	}
+}
+
+func runSecretsScan(paths []string, strictMode bool) {
+	baselinePath := defaultBaseline()
+	var baseline *secrets.Baseline
+	if _, err := os.Stat(baselinePath); err == nil || secretsBaseline != "" {
+		if baseline, err = secrets.LoadBaseline(baselinePath); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	files := sharedFiles(paths)
+	var accepted scan.Accepted
+	if baseline != nil {
+		accepted = func(file scan.File, key, value string) bool {
+			return baseline.Accepts(baselineRel(baselinePath, file), key, value)
+		}
+	}
+	results := scan.Secrets(files, types.Config{Strict: strictMode, Dialect: dialect}, detector, accepted, secretsJobs)
+
+	reports := make([]types.Report, 0, len(results))
+	found, known, failed := 0, 0, false
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintln(os.Stderr, r.Err)
+		}
+		reports = append(reports, r.Report)
+		found += len(r.Report.Errors) + len(r.Report.Warnings)
+		known += len(r.Secrets)
+		failed = failed || r.Err != nil || len(r.Report.Errors) > 0
+	}
+	known -= found
+
+	if reportFormat != "text" {
+		writeReports(reports)
+	} else {
+		// a directory may hold a single shared file, the findings still have to say which one
+		report.Text{Headers: true}.Report(os.Stdout, reports)
+		fmt.Printf("\nChecked %d file(s): %d possible secret(s)", len(results), found)
+		if baseline != nil {
+			fmt.Printf(", %d more accepted in %s", known, baselinePath)
+		}
+		fmt.Println()
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// sharedFiles turns the arguments of a secrets command into files: directories are walked for
+// the files meant to be committed, anything named directly is taken as it is
+func sharedFiles(paths []string) []scan.File {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	names, err := expandFiles(paths)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var files []scan.File
+	for _, name := range names {
+		if info, err := os.Stat(name); err == nil && info.IsDir() {
+			found, err := scan.Find(name, scan.Options{
+				Include: append(append([]string(nil), scan.DefaultInclude...), scan.SchemaNames...),
+				Exclude: secretsExclude,
+			})
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			for _, file := range found {
+				if scan.Public(file.Kind) {
+					file.Rel = filepath.ToSlash(filepath.Join(name, file.Rel))
+					files = append(files, file)
+				}
+			}
+			continue
+		}
+		files = append(files, scan.NewFile(name))
+	}
+	if len(files) == 0 {
+		fmt.Printf("No shared .env or schema files found under %s\n", strings.Join(paths, ", "))
+		os.Exit(0)
+	}
+	return files
+}
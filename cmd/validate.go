@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/schema"
+	"github.com/adnaneAkk/envdoc/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaFile string
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [.env file]",
+	Short: "Validate a .env file against a schema",
+	Long:  `Check a .env file against a schema.json or schema.yaml written by envdoc schema`,
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			envFile = args[0]
+		} else {
+			envFile = defaultEnvFile()
+		}
+		runSchemaValidation(envFile, schemaFile, strict)
+	},
+}
+
+func init() {
+	validateCmd.Flags().StringVar(&schemaFile, "schema", "schema.json", "Schema file to validate against (json|yaml)")
+	rootCmd.AddCommand(validateCmd)
+}
+
+func runSchemaValidation(filename, schemaPath string, strictMode bool) {
+	config := types.Config{
+		Strict:     strictMode,
+		Expand:     !noExpand,
+		ProcessEnv: processEnv,
+		Dialect:    dialect,
+	}
+
+	envVarMap, errors, warnings, err := parser.ParseFile(filename, config)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	schemaData, err := schema.Load(schemaPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	schemaErrors, schemaWarnings := schema.Validate(envVarMap, schemaData, config)
+	errors = append(errors, schemaErrors...)
+	warnings = append(warnings, schemaWarnings...)
+
+	if reportFormat != "text" {
+		writeReports([]types.Report{{File: parser.DisplayName(filename), Errors: errors, Warnings: warnings}})
+	} else {
+		printIssues(filename, errors, warnings)
+
+		if len(errors) == 0 && len(warnings) == 0 {
+			fmt.Printf("\n✓ %s matches %s! Checked %d environment variables.\n", parser.DisplayName(filename), schemaPath, len(envVarMap))
+		}
+	}
+
+	// Exit with error code if errors found
+	if len(errors) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,94 @@
+package compare
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/adnaneAkk/envdoc/internal/secrets"
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+const (
+	MissingKey      = "missing key"
+	DifferentValues = "difference in value"
+)
+
+// Comparison is the diff of two files, Differences sorted by key
+type Comparison struct {
+	File1       string        `json:"file1" yaml:"file1"`
+	File2       string        `json:"file2" yaml:"file2"`
+	Differences types.DiffMap `json:"differences" yaml:"differences"`
+	Identical   int           `json:"identical" yaml:"identical"` // keys both files set to the same value
+}
+
+// Diff compares two parsed files, the names only go into the messages
+func Diff(file1, file2 string, vars1, vars2 types.EnvVarMap) Comparison {
+	c := Comparison{File1: file1, File2: file2, Differences: types.DiffMap{}}
+
+	for _, key := range sortedKeys(vars1, vars2) {
+		value1, in1 := vars1[key]
+		value2, in2 := vars2[key]
+		if in1 && in2 && value1.Value == value2.Value {
+			c.Identical++
+			continue
+		}
+
+		d := types.Diff{DiffType: DifferentValues, KeyName: key}
+		if in1 {
+			d.Value1, d.Line1 = value1.Value, value1.LineNum
+		}
+		if in2 {
+			d.Value2, d.Line2 = value2.Value, value2.LineNum
+		}
+		if !in1 || !in2 {
+			d.DiffType = MissingKey
+		}
+		d.Message = c.describe(d)
+		c.Differences = append(c.Differences, d)
+	}
+	return c
+}
+
+// Redact hides both values of every difference where one of them looks like a secret, messages included
+func (c *Comparison) Redact(detector *secrets.Detector) {
+	for i := range c.Differences {
+		d := &c.Differences[i]
+		d.Sensitive = (d.Line1 != 0 && detector.IsRedacted(d.KeyName, d.Value1)) || (d.Line2 != 0 && detector.IsRedacted(d.KeyName, d.Value2))
+		if !d.Sensitive {
+			continue
+		}
+		if d.Line1 != 0 {
+			d.Value1 = "[SENSITIVE]"
+		}
+		if d.Line2 != 0 {
+			d.Value2 = "[SENSITIVE]"
+		}
+		d.Message = c.describe(*d)
+	}
+}
+
+func (c Comparison) describe(d types.Diff) string {
+	switch {
+	case d.Line2 == 0:
+		return fmt.Sprintf("file %s is missing the key %s file %s has (line %d)", c.File2, d.KeyName, c.File1, d.Line1)
+	case d.Line1 == 0:
+		return fmt.Sprintf("file %s is missing the key %s file %s has (line %d)", c.File1, d.KeyName, c.File2, d.Line2)
+	}
+	return fmt.Sprintf("key %s has value %s in file %s (line %d), but %s in file %s (line %d)", d.KeyName, d.Value1, c.File1, d.Line1, d.Value2, c.File2, d.Line2)
+}
+
+// sortedKeys is every key set in any of the maps, once, in order
+func sortedKeys(maps ...types.EnvVarMap) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, vars := range maps {
+		for key := range vars {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,217 @@
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxCell is how much of a value the text matrix shows before cutting it short
+const maxCell = 24
+
+// Formats are the formats WriteMatrix and WriteDiff understand
+var Formats = []string{"text", "markdown", "json", "yaml"}
+
+// WriteMatrix renders m in one of Formats
+func WriteMatrix(w io.Writer, m Matrix, format string) error {
+	switch format {
+	case "text":
+		return writeMatrixText(w, m)
+	case "markdown":
+		return writeMatrixMarkdown(w, m)
+	}
+	return encode(w, m, format)
+}
+
+// WriteDiff renders c in one of Formats
+func WriteDiff(w io.Writer, c Comparison, format string) error {
+	switch format {
+	case "text":
+		return writeDiffText(w, c)
+	case "markdown":
+		return writeDiffMarkdown(w, c)
+	}
+	return encode(w, c, format)
+}
+
+// encode covers the machine-readable formats
+func encode(w io.Writer, v any, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	return fmt.Errorf("unknown format: %s (use %s)", format, strings.Join(Formats, ", "))
+}
+
+func writeDiffText(w io.Writer, c Comparison) error {
+	if len(c.Differences) == 0 {
+		_, err := fmt.Fprintln(w, "\n✓ Files are identical")
+		return err
+	}
+
+	fmt.Fprintf(w, "\n=== Comparison: %s vs %s ===\n", c.File1, c.File2)
+	for _, d := range c.Differences {
+		switch {
+		case d.Line2 == 0:
+			fmt.Fprintf(w, "  - %-20s = %s (only in %s)\n", d.KeyName, d.Value1, c.File1)
+		case d.Line1 == 0:
+			fmt.Fprintf(w, "  + %-20s = %s (only in %s)\n", d.KeyName, d.Value2, c.File2)
+		default:
+			fmt.Fprintf(w, "  ~ %-20s %q → %q\n", d.KeyName, d.Value1, d.Value2)
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%d difference(s) found\n", len(c.Differences))
+	return err
+}
+
+func writeDiffMarkdown(w io.Writer, c Comparison) error {
+	if len(c.Differences) == 0 {
+		_, err := fmt.Fprintf(w, "%s and %s are identical (%d key(s)).\n", c.File1, c.File2, c.Identical)
+		return err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "| Key | Change | %s | %s |\n", markdownEscape(c.File1), markdownEscape(c.File2))
+	sb.WriteString("|-----|--------|-----|-----|\n")
+	for _, d := range c.Differences {
+		change := "changed"
+		switch {
+		case d.Line2 == 0:
+			change = "removed"
+		case d.Line1 == 0:
+			change = "added"
+		}
+		fmt.Fprintf(&sb, "| `%s` | %s | %s | %s |\n", d.KeyName, change, markdownValue(d.Value1, d.Line1), markdownValue(d.Value2, d.Line2))
+	}
+	fmt.Fprintf(&sb, "\n%d difference(s), %d key(s) identical.\n", len(c.Differences), c.Identical)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// markdownValue shows a value in a table cell, — when the file doesn't set the key
+func markdownValue(value string, line int) string {
+	if line == 0 {
+		return "—"
+	}
+	return markdownCode(value)
+}
+
+func markdownCode(value string) string {
+	return "`" + markdownEscape(strings.ReplaceAll(value, "\n", `\n`)) + "`"
+}
+
+func writeMatrixText(w io.Writer, m Matrix) error {
+	fmt.Fprintf(w, "\n=== Comparison: %s ===\n", strings.Join(m.Files, " vs "))
+	if len(m.Rows) == 0 {
+		_, err := fmt.Fprintf(w, "\n✓ All %d key(s) are the same in every file\n", m.Identical)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "KEY\t%s\n", strings.Join(m.Files, "\t"))
+	for _, row := range m.Rows {
+		cells := make([]string, len(row.Cells))
+		for i, cell := range row.Cells {
+			cells[i] = textCell(cell)
+		}
+		line := row.Key + "\t" + strings.Join(cells, "\t")
+		if row.MissingInOne {
+			line += "\t⚠ only missing in " + missingFile(m, row)
+		}
+		fmt.Fprintln(tw, line)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\n≠ differs from most files, — not set, ⚠ set everywhere but one file")
+	_, err := fmt.Fprintf(w, "%d key(s) differ, %d missing in a single file, %d identical everywhere\n", len(m.Rows), m.MissingInOne(), m.Identical)
+	return err
+}
+
+func textCell(cell Cell) string {
+	if cell.State == Absent {
+		return "—"
+	}
+	value := strings.ReplaceAll(cell.Value, "\n", `\n`)
+	if runes := []rune(value); len(runes) > maxCell {
+		value = string(runes[:maxCell-3]) + "..."
+	}
+	if value == "" {
+		value = `""`
+	}
+	if cell.State == Different {
+		return "≠ " + value
+	}
+	return value
+}
+
+func writeMatrixMarkdown(w io.Writer, m Matrix) error {
+	if len(m.Rows) == 0 {
+		_, err := fmt.Fprintf(w, "All %d key(s) are the same in %s.\n", m.Identical, strings.Join(m.Files, ", "))
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Key |")
+	for _, file := range m.Files {
+		sb.WriteString(" " + markdownEscape(file) + " |")
+	}
+	sb.WriteString("\n|-----|" + strings.Repeat("-----|", len(m.Files)) + "\n")
+
+	for _, row := range m.Rows {
+		key := "`" + row.Key + "`"
+		if row.MissingInOne {
+			key = "⚠ " + key
+		}
+		sb.WriteString("| " + key + " |")
+		for _, cell := range row.Cells {
+			sb.WriteString(" " + markdownCell(cell) + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	fmt.Fprintf(&sb, "\n%d key(s) differ, %d missing in a single file (⚠), %d identical everywhere. ≠ marks values that differ from most files, — keys that aren't set.\n",
+		len(m.Rows), m.MissingInOne(), m.Identical)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func markdownCell(cell Cell) string {
+	if cell.State == Absent {
+		return "—"
+	}
+	value := markdownCode(cell.Value)
+	if cell.State == Different {
+		return "≠ " + value
+	}
+	return value
+}
+
+// markdownEscape keeps a value from breaking out of its table cell
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "`", "'").Replace(s)
+}
+
+// missingFile names the one file a MissingInOne row is absent from
+func missingFile(m Matrix, row Row) string {
+	for i, cell := range row.Cells {
+		if cell.State == Absent {
+			return m.Files[i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+package compare
+
+import (
+	"github.com/adnaneAkk/envdoc/internal/secrets"
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// State says how one environment's value for a key relates to the others
+type State string
+
+const (
+	Absent    State = "absent"    // the environment doesn't set the key
+	Present   State = "present"   // only this environment sets it, there's nothing to compare with
+	Equal     State = "equal"     // same value as most environments
+	Different State = "different" // a value the majority doesn't have
+)
+
+// Cell is one key in one environment
+type Cell struct {
+	State State  `json:"state" yaml:"state"`
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+	Line  int    `json:"line,omitempty" yaml:"line,omitempty"`
+}
+
+// Row is one key across every environment, cells in the order of Matrix.Files
+type Row struct {
+	Key       string `json:"key" yaml:"key"`
+	Cells     []Cell `json:"cells" yaml:"cells"`
+	Sensitive bool   `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+	// set in all environments but one, which is usually a forgotten key rather than a choice
+	MissingInOne bool `json:"missingInOne,omitempty" yaml:"missingInOne,omitempty"`
+}
+
+// Matrix lays out keys × environments. Rows are sorted by key
+type Matrix struct {
+	Files     []string `json:"files" yaml:"files"`
+	Rows      []Row    `json:"rows" yaml:"rows"`
+	Identical int      `json:"identical" yaml:"identical"` // keys set to the same value everywhere, left out of Rows
+}
+
+// Build compares the parsed files, maps[i] being the variables of files[i]. Only keys that are
+// missing somewhere or have different values get a row
+func Build(files []string, maps []types.EnvVarMap) Matrix {
+	m := Matrix{Files: files, Rows: []Row{}}
+
+	for _, key := range sortedKeys(maps...) {
+		row, same := buildRow(key, maps)
+		if same {
+			m.Identical++
+			continue
+		}
+		m.Rows = append(m.Rows, row)
+	}
+	return m
+}
+
+// buildRow fills in one key, same is true when every environment has it with one value
+func buildRow(key string, maps []types.EnvVarMap) (Row, bool) {
+	row := Row{Key: key, Cells: make([]Cell, len(maps))}
+
+	// the reference value is the most common one, ties go to the earlier file
+	counts := map[string]int{}
+	var order []string
+	present := 0
+	for i, vars := range maps {
+		envVar, ok := vars[key]
+		if !ok {
+			row.Cells[i] = Cell{State: Absent}
+			continue
+		}
+		present++
+		row.Cells[i] = Cell{Value: envVar.Value, Line: envVar.LineNum}
+		if counts[envVar.Value] == 0 {
+			order = append(order, envVar.Value)
+		}
+		counts[envVar.Value]++
+	}
+	reference := order[0]
+	for _, value := range order {
+		if counts[value] > counts[reference] {
+			reference = value
+		}
+	}
+
+	for i := range row.Cells {
+		cell := &row.Cells[i]
+		switch {
+		case cell.State == Absent:
+		case present == 1:
+			cell.State = Present
+		case cell.Value == reference:
+			cell.State = Equal
+		default:
+			cell.State = Different
+		}
+	}
+	row.MissingInOne = len(maps) > 2 && present == len(maps)-1
+	return row, present == len(maps) && len(order) == 1
+}
+
+// Redact hides the values of every row where one of them looks like a secret
+func (m *Matrix) Redact(detector *secrets.Detector) {
+	for r := range m.Rows {
+		row := &m.Rows[r]
+		for _, cell := range row.Cells {
+			if cell.State != Absent && detector.IsRedacted(row.Key, cell.Value) {
+				row.Sensitive = true
+			}
+		}
+		if !row.Sensitive {
+			continue
+		}
+		for c := range row.Cells {
+			if row.Cells[c].State != Absent {
+				row.Cells[c].Value = "[SENSITIVE]"
+			}
+		}
+	}
+}
+
+// MissingInOne counts the rows where a single environment lacks the key
+func (m Matrix) MissingInOne() int {
+	n := 0
+	for _, row := range m.Rows {
+		if row.MissingInOne {
+			n++
+		}
+	}
+	return n
+}
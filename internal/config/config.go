@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/adnaneAkk/envdoc/internal/lint"
+	"github.com/adnaneAkk/envdoc/internal/secrets"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the project config file envdoc looks for
+const FileName = ".envdoc.yaml"
+
+// Project holds the settings from a .envdoc.yaml. Pointer fields are nil when the file
+// doesn't set them, so a CLI default is never mistaken for a choice
+type Project struct {
+	Strict       *bool             `yaml:"strict"`
+	Dialect      string            `yaml:"dialect"`
+	Expand       *bool             `yaml:"expand"`
+	ProcessEnv   *bool             `yaml:"process-env"`
+	OutputFormat string            `yaml:"output-format"`
+	Files        []string          `yaml:"files"`  // files checked when none are given
+	Schema       string            `yaml:"schema"` // schema used by validate
+	Rules        map[string]string `yaml:"rules"`  // rule ID or name -> off, warn or error
+	Secrets      secrets.Rules     `yaml:"secrets"`
+	Booleans     []string          `yaml:"booleans"` // words accepted by the boolean type
+	Scan         Scan              `yaml:"scan"`
+
+	Path string `yaml:"-"`
+}
+
+// Scan narrows down the files envdoc scan picks up, patterns use .gitignore syntax
+type Scan struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// Find looks for FileName in dir and each of its parents, returning "" when there is none
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		candidate := filepath.Join(dir, FileName)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads and checks a project config file. Relative files and schema paths are
+// resolved against the directory the config lives in
+func Load(path string) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening config %s: %v", path, err)
+	}
+
+	project := &Project{}
+	if err := yaml.Unmarshal(data, project); err != nil {
+		return nil, fmt.Errorf("error reading config %s: %v", path, err)
+	}
+	project.Path = path
+
+	for rule, severity := range project.Rules {
+		if _, ok := lint.Lookup(rule); !ok {
+			return nil, fmt.Errorf("config %s: unknown rule %s", path, rule)
+		}
+		if _, err := lint.ParseSeverity(severity); err != nil {
+			return nil, fmt.Errorf("config %s: rule %s: %v", path, rule, err)
+		}
+	}
+
+	if _, err := secrets.New(project.Secrets); err != nil {
+		return nil, fmt.Errorf("config %s: %v", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i, file := range project.Files {
+		project.Files[i] = relativeTo(dir, file)
+	}
+	if project.Schema != "" {
+		project.Schema = relativeTo(dir, project.Schema)
+	}
+
+	return project, nil
+}
+
+// relativeTo keeps the paths in a config meaning the same wherever envdoc is run from
+func relativeTo(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return filepath.Join(dir, path)
+	}
+	rel, err := filepath.Rel(wd, filepath.Join(dir, path))
+	if err != nil {
+		return filepath.Join(dir, path)
+	}
+	return rel
+}
@@ -0,0 +1,208 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// Entry is one logical line of a .env file as the parser read it, which is what rules look at.
+// Blank lines and comments never become entries
+type Entry struct {
+	LineNum int
+	EndLine int // same as LineNum unless the value spans several lines
+	Key     string
+	Raw     string // the value as written, trimmed
+	Value   string // the value after quotes, escapes and inline comments
+	Quote   byte   // '"' or '\'' when the value was quoted
+
+	HasEquals      bool
+	Dialect        string
+	SpaceCut       bool   // the dialect stopped the unquoted value at whitespace, Value has what it kept
+	Unclosed       bool   // the quote never closes
+	DanglingEscape bool   // the value ends in a lone backslash
+	Trailing       string // text after the closing quote that isn't a comment
+	TrailingLine   int    // line the trailing text is on
+	Swallowed      bool   // a multiline value ran over lines that look like assignments
+	Heredoc        string // heredoc terminator, "" when the value isn't a heredoc
+	HeredocOpen    bool   // the heredoc terminator never shows up
+	FirstLine      int    // where the key was first assigned when this is a duplicate, 0 otherwise
+	PrevLine       int    // where the occurrence just before this one starts and ends, for duplicates
+	PrevEndLine    int
+
+	// where things are on the first line, columns only, the parser fills in file offsets afterwards
+	Text      types.Span // the line without surrounding whitespace
+	KeySpan   types.Span
+	Equals    types.Span
+	ValueSpan types.Span // the value as written, up to the closing quote or the end of the first line
+
+	Lines []string // the entry's source lines, as read
+}
+
+// Columns is the span of n bytes starting at index i of a line
+func Columns(i, n int) types.Span {
+	return types.Span{Column: i + 1, EndColumn: i + 1 + n}
+}
+
+// Rule is a check that runs over every parsed entry
+type Rule interface {
+	Info() Info
+	Check(e Entry) []types.Issue
+}
+
+// entryRule is a Rule made from a function
+type entryRule struct {
+	info  Info
+	check func(e Entry) []types.Issue
+}
+
+func (r entryRule) Info() Info                  { return r.info }
+func (r entryRule) Check(e Entry) []types.Issue { return r.check(e) }
+
+func rule(id string, check func(e Entry) []types.Issue) Rule {
+	return entryRule{info: mustLookup(id), check: check}
+}
+
+// issue is the usual single finding for an entry's key on its first line, at span
+func issue(e Entry, span types.Span, message string) []types.Issue {
+	return []types.Issue{{LineNum: e.LineNum, Message: message, KeyName: e.Key, Span: span}}
+}
+
+// lastByte narrows a span to its final byte
+func lastByte(s types.Span) types.Span {
+	return types.Span{Column: max(s.EndColumn-1, s.Column), EndColumn: s.EndColumn}
+}
+
+var strictKeyRegex = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// rules run in this order on every entry
+var rules = []Rule{
+	rule("ED002", func(e Entry) []types.Issue {
+		if e.HasEquals {
+			return nil
+		}
+		return issue(e, e.Text, "missing '='")
+	}),
+	rule("ED004", func(e Entry) []types.Issue {
+		if !e.HasEquals || e.Key != "" {
+			return nil
+		}
+		return issue(e, e.Equals, "missing key")
+	}),
+	rule("ED005", func(e Entry) []types.Issue {
+		if e.Key == "" || e.Raw != "" {
+			return nil
+		}
+		return issue(e, e.Equals, "missing value")
+	}),
+	rule("ED010", func(e Entry) []types.Issue {
+		if !e.Swallowed {
+			return nil
+		}
+		return issue(e, e.ValueSpan, fmt.Sprintf("quoted value runs to line %d over lines that look like assignments, check for a missing closing quote", e.EndLine))
+	}),
+	rule("ED009", func(e Entry) []types.Issue {
+		if e.Trailing == "" {
+			return nil
+		}
+		found := types.Issue{LineNum: e.TrailingLine, Message: "content after closing quote", KeyName: e.Key}
+		line := e.Lines[e.TrailingLine-e.LineNum]
+		if idx := strings.LastIndex(line, e.Trailing); idx != -1 {
+			found.Span = Columns(idx, len(e.Trailing))
+			found.Fix = &types.Fix{
+				Description: "turn the trailing text into a comment",
+				Line:        e.TrailingLine,
+				EndLine:     e.TrailingLine,
+				Text:        strings.TrimRight(line[:idx], " \t") + " # " + line[idx:],
+			}
+		}
+		return []types.Issue{found}
+	}),
+	rule("ED008", func(e Entry) []types.Issue {
+		if !e.DanglingEscape {
+			return nil
+		}
+		found := issue(e, lastByte(e.ValueSpan), "dangling escape at end of value")
+		// only unquoted values, in a quoted one the backslash is eating the closing quote
+		// the loaded value starts where the span does and may stop short of it, at a space
+		end := e.ValueSpan.Column - 1 + len(e.Value)
+		if e.Quote == 0 && e.ValueSpan.Column > 0 && end <= len(e.Lines[0]) {
+			found[0].Fix = &types.Fix{
+				Description: "escape the backslash",
+				Line:        e.LineNum,
+				EndLine:     e.LineNum,
+				Text:        e.Lines[0][:end] + `\` + e.Lines[0][end:],
+			}
+		}
+		return found
+	}),
+	rule("ED001", func(e Entry) []types.Issue {
+		if !e.Unclosed {
+			return nil
+		}
+		return issue(e, Columns(e.ValueSpan.Column-1, 1), "unclosed quoted value")
+	}),
+	rule("ED011", func(e Entry) []types.Issue {
+		if !e.HeredocOpen {
+			return nil
+		}
+		return issue(e, e.ValueSpan, fmt.Sprintf("heredoc is never closed with %s", e.Heredoc))
+	}),
+	rule("ED007", func(e Entry) []types.Issue {
+		if e.Key == "" || !e.SpaceCut {
+			return nil
+		}
+		return issue(e, e.ValueSpan, fmt.Sprintf("unquoted value contains whitespace, %s stops the value at the first space", e.Dialect))
+	}),
+	rule("ED003", func(e Entry) []types.Issue {
+		if e.Key == "" || strictKeyRegex.MatchString(e.Key) {
+			return nil
+		}
+		found := issue(e, e.KeySpan, "invalid strict key (must be uppercase with underscores)")
+		// a key with whitespace in it is more likely a typo than a name, renaming it would guess
+		start, end := e.KeySpan.Column-1, e.KeySpan.EndColumn-1
+		if !strings.ContainsAny(e.Key, " \t") && start >= 0 && end <= len(e.Lines[0]) && e.Lines[0][start:end] == e.Key {
+			key := upperKey(e.Key)
+			found[0].Fix = &types.Fix{
+				Description: "rename to " + key,
+				Line:        e.LineNum,
+				EndLine:     e.LineNum,
+				Text:        e.Lines[0][:start] + key + e.Lines[0][end:],
+			}
+		}
+		return found
+	}),
+	rule("ED006", func(e Entry) []types.Issue {
+		if e.FirstLine == 0 {
+			return nil
+		}
+		found := issue(e, e.KeySpan, fmt.Sprintf("Duplicate key detected; first occurrence on line %d", e.FirstLine))
+		found[0].Fix = &types.Fix{Description: "remove this assignment", Line: e.LineNum, EndLine: e.EndLine, Delete: true}
+		if keepDuplicate == KeepLast {
+			found[0].Fix = &types.Fix{
+				Description: fmt.Sprintf("remove the assignment on line %d", e.PrevLine),
+				Line:        e.PrevLine,
+				EndLine:     e.PrevEndLine,
+				Delete:      true,
+			}
+		}
+		return found
+	}),
+}
+
+// Check runs every rule over e and files what they find, unless a comment silences the rule on e's lines
+func Check(e Entry, strict bool, sups *Suppressions, errors, warnings *[]types.Issue) {
+	for _, r := range rules {
+		id := r.Info().ID
+		if SeverityOf(id, strict) == Off {
+			continue
+		}
+		for _, found := range r.Check(e) {
+			if !sups.Suppressed(id, e.LineNum, e.EndLine) {
+				Add(id, found, strict, errors, warnings)
+			}
+		}
+	}
+}
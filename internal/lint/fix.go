@@ -0,0 +1,101 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// Which copy of a duplicated key the ED006 fix keeps
+const (
+	KeepFirst = "first" // what envdoc reads
+	KeepLast  = "last"  // what most loaders read
+)
+
+var keepDuplicate = KeepFirst
+
+// SetKeepDuplicate picks the copy of a duplicated key that fixes keep
+func SetKeepDuplicate(keep string) error {
+	if keep != KeepFirst && keep != KeepLast {
+		return fmt.Errorf("unknown duplicate policy %q (use first or last)", keep)
+	}
+	keepDuplicate = keep
+	return nil
+}
+
+// Apply makes the fixes attached to issues in src and returns the result with the issues it fixed.
+// Fixes touching lines an earlier fix already changed are skipped, running again picks them up
+func Apply(src string, issues []types.Issue) (string, []types.Issue) {
+	var fixable []types.Issue
+	for _, issue := range issues {
+		if issue.Fix != nil {
+			fixable = append(fixable, issue)
+		}
+	}
+	sort.SliceStable(fixable, func(i, j int) bool { return fixable[i].Fix.Line < fixable[j].Fix.Line })
+
+	newline := "\n"
+	if strings.Contains(src, "\r\n") {
+		newline = "\r\n"
+	}
+	finalNewline := strings.HasSuffix(src, "\n")
+	lines := strings.Split(strings.TrimSuffix(src, newline), newline)
+	if src == "" {
+		lines = nil
+	}
+
+	// replacement for each line that changes, nil means removed
+	edits := map[int][]string{}
+	var applied []types.Issue
+	lastLine := 0
+	for _, issue := range fixable {
+		fix := issue.Fix
+		if fix.Line <= lastLine || fix.Line < 1 || fix.EndLine > len(lines) {
+			continue
+		}
+		for line := fix.Line; line <= fix.EndLine; line++ {
+			edits[line] = nil
+		}
+		if !fix.Delete {
+			edits[fix.Line] = strings.Split(fix.Text, "\n")
+		}
+		lastLine = fix.EndLine
+		applied = append(applied, issue)
+	}
+
+	var out []string
+	for i, line := range lines {
+		if replacement, ok := edits[i+1]; ok {
+			out = append(out, replacement...)
+			continue
+		}
+		out = append(out, line)
+	}
+
+	result := strings.Join(out, newline)
+	if finalNewline && len(out) > 0 {
+		result += newline
+	}
+	return result, applied
+}
+
+// upperKey turns a key into the UPPER_SNAKE_CASE strict mode wants
+func upperKey(key string) string {
+	var sb strings.Builder
+	for i, r := range strings.ToUpper(key) {
+		switch {
+		case r >= 'A' && r <= 'Z', r == '_':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
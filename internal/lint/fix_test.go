@@ -0,0 +1,40 @@
+package lint_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adnaneAkk/envdoc/internal/lint"
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// fixes have to change the key or value they're about, not the first text on the line that looks like it
+func TestFixes(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		src     string
+		want    string
+	}{
+		{"key after export", "docker-compose", "export exp=1\n", "export EXP=1\n"},
+		{"key inside export", "docker-compose", "export e=1\n", "export E=1\n"},
+		{"key in its value", "envdoc", "db_host=db_host\n", "DB_HOST=db_host\n"},
+		{"key with a space", "envdoc", "export EXP=1\n", "export EXP=1\n"},
+		{"escape repeated in comment", "envdoc", `DIR=C:\temp\ # C:\temp\` + "\n", `DIR=C:\temp\\ # C:\temp\` + "\n"},
+		{"escape before a space", "shell", `DIR=a\ b\ c\` + "\n", `DIR=a\ b\ c\\` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errors, warnings, err := parser.Parse(strings.NewReader(tt.src), types.Config{Strict: true, Dialect: tt.dialect})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := lint.Apply(tt.src, append(errors, warnings...))
+			if got != tt.want {
+				t.Errorf("fixed to %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// Severity says where an issue ends up: dropped, with the warnings or with the errors
+type Severity string
+
+const (
+	Off   Severity = "off"
+	Warn  Severity = "warn"
+	Error Severity = "error"
+)
+
+// ParseSeverity accepts the severities a config file may use
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(s) {
+	case Off, Warn, Error:
+		return Severity(s), nil
+	}
+	return "", fmt.Errorf("unknown severity %q (use off, warn or error)", s)
+}
+
+// Info describes a rule. The ID never changes once released, the name is a readable alias for it
+type Info struct {
+	ID       string   // ED001, ED002, ...
+	Name     string   // kebab-case, used in configs and as the issue type
+	Summary  string   // one line for docs and SARIF
+	Severity Severity // default severity
+	Strict   Severity // severity under --strict
+}
+
+const docsURL = "https://github.com/adnaneAkk/envdoc/blob/main/docs/rules.md"
+
+// Slug is the anchor of the rule in docs/rules.md
+func (i Info) Slug() string {
+	return strings.ToLower(i.ID) + "-" + i.Name
+}
+
+// URL points at the rule's documentation
+func (i Info) URL() string {
+	return docsURL + "#" + i.Slug()
+}
+
+// catalog lists every rule envdoc can report, ED0xx for the file itself, ED1xx for schema checks
+// and ED2xx for secret scanning
+var catalog = []Info{
+	{"ED001", "unclosed-quote", "Quoted value never closes", Warn, Error},
+	{"ED002", "missing-equals", "Line has no '=' between key and value", Error, Error},
+	{"ED003", "lowercase-key", "Key is not UPPER_SNAKE_CASE", Off, Error},
+	{"ED004", "missing-key", "Assignment has nothing left of the '='", Error, Error},
+	{"ED005", "empty-value", "Key is assigned an empty value", Warn, Warn},
+	{"ED006", "duplicate-key", "Key is assigned more than once", Warn, Error},
+	{"ED007", "unquoted-whitespace", "Unquoted value contains whitespace the dialect would cut", Warn, Warn},
+	{"ED008", "dangling-escape", "Value ends in a lone backslash", Warn, Error},
+	{"ED009", "content-after-quote", "Text follows the closing quote", Warn, Error},
+	{"ED010", "multiline-swallow", "Quoted value runs over lines that look like assignments", Warn, Warn},
+	{"ED011", "unclosed-heredoc", "Heredoc never reaches its terminator", Warn, Error},
+	{"ED012", "undefined-variable", "Reference to a variable that is not defined", Warn, Warn},
+	{"ED013", "unset-variable", "${VAR:?} or ${VAR?} reference to an unset variable", Error, Error},
+	{"ED014", "variable-cycle", "Variables reference each other in a loop", Error, Error},
+	{"ED015", "unused-suppression", "envdoc:ignore or envdoc:disable comment that silences nothing", Warn, Warn},
+	{"ED016", "invalid-suppression", "envdoc: comment with no rules, an unknown rule or nothing to enable", Warn, Error},
+	{"ED017", "invalid-reference", "Malformed ${...} reference, unterminated or with an unknown operator", Warn, Error},
+
+	{"ED101", "required-key", "Required key is missing or empty", Error, Error},
+	{"ED102", "unknown-type", "Schema declares a type envdoc doesn't know", Warn, Warn},
+	{"ED103", "type-mismatch", "Value doesn't match the schema type", Error, Error},
+	{"ED104", "undeclared-key", "Key is not declared in the schema", Warn, Error},
+	{"ED105", "enum", "Value is not one of the schema's enum values", Error, Error},
+	{"ED106", "min", "Value is below the schema minimum", Error, Error},
+	{"ED107", "max", "Value is above the schema maximum", Error, Error},
+	{"ED108", "pattern", "Value doesn't match the schema pattern", Error, Error},
+	{"ED109", "min-length", "Value is shorter than the schema allows", Error, Error},
+	{"ED110", "max-length", "Value is longer than the schema allows", Error, Error},
+
+	{"ED201", "committed-secret", "A file meant to be shared holds what looks like a real secret", Error, Error},
+}
+
+// severity overrides from the project config, keyed by rule ID
+var overrides = map[string]Severity{}
+
+// All returns every rule in ID order
+func All() []Info {
+	return append([]Info(nil), catalog...)
+}
+
+// Lookup finds a rule by ID (ED001) or name (unclosed-quote)
+func Lookup(idOrName string) (Info, bool) {
+	for _, info := range catalog {
+		if strings.EqualFold(info.ID, idOrName) || info.Name == idOrName {
+			return info, true
+		}
+	}
+	return Info{}, false
+}
+
+// SetSeverity overrides a rule's severity, whatever --strict says
+func SetSeverity(idOrName string, severity Severity) error {
+	info, ok := Lookup(idOrName)
+	if !ok {
+		return fmt.Errorf("unknown rule: %s", idOrName)
+	}
+	overrides[info.ID] = severity
+	return nil
+}
+
+// SeverityOf is the severity issues of rule id are reported with
+func SeverityOf(id string, strict bool) Severity {
+	if severity, ok := overrides[id]; ok {
+		return severity
+	}
+	info := mustLookup(id)
+	if strict {
+		return info.Strict
+	}
+	return info.Severity
+}
+
+// Add stamps issue with rule id and files it under errors or warnings, or drops it if the rule is off
+func Add(id string, issue types.Issue, strict bool, errors, warnings *[]types.Issue) {
+	info := mustLookup(id)
+	issue.Rule = info.ID
+	issue.IssueType = info.Name
+
+	switch SeverityOf(id, strict) {
+	case Error:
+		*errors = append(*errors, issue)
+	case Warn:
+		*warnings = append(*warnings, issue)
+	}
+}
+
+func mustLookup(id string) Info {
+	info, ok := Lookup(id)
+	if !ok {
+		panic("lint: unknown rule " + id)
+	}
+	return info
+}
@@ -0,0 +1,160 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// directiveRegex finds suppression comments:
+//
+//	KEY=value # envdoc:ignore ED003            this line
+//	# envdoc:disable-next-line ED005, ED007    the line below
+//	# envdoc:disable ED006 ... # envdoc:enable ED006    everything in between
+var directiveRegex = regexp.MustCompile(`#\s*envdoc:(ignore|disable-next-line|disable|enable)\b([^#]*)`)
+
+// suppression silences one rule over a range of lines
+type suppression struct {
+	line      int // where the comment is
+	span      types.Span
+	directive string
+	rule      string
+	from, to  int // to is 0 while a disable block is still open
+	used      bool
+}
+
+// Suppressions are the envdoc: comments found in one file
+type Suppressions struct {
+	list     []*suppression
+	problems []types.Issue // directives that make no sense, reported as ED016
+}
+
+// ParseSuppressions reads the suppression comments in a file's lines
+func ParseSuppressions(lines []string) *Suppressions {
+	s := &Suppressions{}
+	for i, line := range lines {
+		lineNum := i + 1
+		loc := directiveRegex.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+		span := Columns(loc[0], len(strings.TrimRight(line[loc[0]:loc[1]], " \t")))
+		directive, names := line[loc[2]:loc[3]], line[loc[4]:loc[5]]
+
+		var rules []string
+		for _, name := range strings.FieldsFunc(names, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			info, ok := Lookup(name)
+			if !ok {
+				s.problem(lineNum, span, fmt.Sprintf("envdoc:%s names unknown rule %s", directive, name))
+				continue
+			}
+			rules = append(rules, info.ID)
+		}
+		if len(rules) == 0 && len(strings.TrimSpace(names)) == 0 {
+			s.problem(lineNum, span, fmt.Sprintf("envdoc:%s needs the rule IDs it applies to", directive))
+			continue
+		}
+
+		for _, rule := range rules {
+			switch directive {
+			case "ignore":
+				s.list = append(s.list, &suppression{line: lineNum, span: span, directive: directive, rule: rule, from: lineNum, to: lineNum})
+			case "disable-next-line":
+				s.list = append(s.list, &suppression{line: lineNum, span: span, directive: directive, rule: rule, from: lineNum + 1, to: lineNum + 1})
+			case "disable":
+				s.list = append(s.list, &suppression{line: lineNum, span: span, directive: directive, rule: rule, from: lineNum})
+			case "enable":
+				if !s.enable(rule, lineNum) {
+					s.problem(lineNum, span, fmt.Sprintf("envdoc:enable %s has no envdoc:disable before it", rule))
+				}
+			}
+		}
+	}
+
+	// a block nobody closed runs to the end of the file
+	for _, sup := range s.list {
+		if sup.to == 0 {
+			sup.to = len(lines)
+		}
+	}
+	return s
+}
+
+func (s *Suppressions) enable(rule string, lineNum int) bool {
+	closed := false
+	for _, sup := range s.list {
+		if sup.rule == rule && sup.directive == "disable" && sup.to == 0 {
+			sup.to = lineNum
+			closed = true
+		}
+	}
+	return closed
+}
+
+func (s *Suppressions) problem(lineNum int, span types.Span, message string) {
+	s.problems = append(s.problems, types.Issue{LineNum: lineNum, Message: message, Span: span})
+}
+
+// Suppressed reports whether rule is silenced anywhere on lines from..to, and marks the comments that did it as used
+func (s *Suppressions) Suppressed(rule string, from, to int) bool {
+	if s == nil {
+		return false
+	}
+	found := false
+	for _, sup := range s.list {
+		if sup.rule == rule && sup.from <= to && from <= sup.to {
+			sup.used = true
+			found = true
+		}
+	}
+	return found
+}
+
+// Filter drops the issues a comment silences, using the lines of the key each issue is about
+func (s *Suppressions) Filter(issues []types.Issue, vars types.EnvVarMap) []types.Issue {
+	var kept []types.Issue
+	for _, issue := range issues {
+		from, to := issue.LineNum, issue.LineNum
+		if envVar, ok := vars[issue.KeyName]; ok {
+			from, to = envVar.LineNum, envVar.EndLine
+		}
+		if !s.Suppressed(issue.Rule, from, to) {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
+
+// Rules lists the rules silenced anywhere on lines from..to, for checks that run after parsing
+func (s *Suppressions) Rules(from, to int) []string {
+	if s == nil {
+		return nil
+	}
+	var rules []string
+	for _, sup := range s.list {
+		if sup.from <= to && from <= sup.to {
+			rules = append(rules, sup.rule)
+		}
+	}
+	return rules
+}
+
+// Report files ED016 for broken comments and ED015 for comments that never silenced anything.
+// Only comments about rules that ran can be unused, checked says which ones did
+func (s *Suppressions) Report(strict bool, checked func(rule string) bool, errors, warnings *[]types.Issue) {
+	for _, problem := range s.problems {
+		Add("ED016", problem, strict, errors, warnings)
+	}
+	for _, sup := range s.list {
+		if sup.used || !checked(sup.rule) || SeverityOf(sup.rule, strict) == Off {
+			continue
+		}
+		Add("ED015", types.Issue{
+			LineNum: sup.line,
+			Span:    sup.span,
+			Message: fmt.Sprintf("envdoc:%s %s never matched anything, remove it", sup.directive, sup.rule),
+		}, strict, errors, warnings)
+	}
+}
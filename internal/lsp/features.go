@@ -0,0 +1,238 @@
+package lsp
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/schema"
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// check parses an open document, a document the dialect can't be loaded for has nothing to report
+func (s *Server) check(uri string) (types.EnvVarMap, []types.Issue, []types.Issue) {
+	envVarMap, errors, warnings, err := parser.Parse(strings.NewReader(s.docs[uri]), s.options.Config)
+	if err != nil {
+		return types.EnvVarMap{}, nil, nil
+	}
+	return envVarMap, errors, warnings
+}
+
+// publish sends the parser and lint rule findings for a document
+func (s *Server) publish(uri string) error {
+	_, errors, warnings := s.check(uri)
+	lines := splitLines(s.docs[uri])
+
+	diagnostics := []diagnostic{}
+	for _, issue := range errors {
+		diagnostics = append(diagnostics, toDiagnostic(lines, issue, severityError))
+	}
+	for _, issue := range warnings {
+		diagnostics = append(diagnostics, toDiagnostic(lines, issue, severityWarning))
+	}
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+// toDiagnostic places an issue in the document, issues without a column cover their whole line
+func toDiagnostic(lines []string, issue types.Issue, severity int) diagnostic {
+	return diagnostic{
+		Range:    issueRange(lines, issue),
+		Severity: severity,
+		Code:     issue.Rule,
+		Source:   "envdoc",
+		Message:  issue.Message,
+	}
+}
+
+func issueRange(lines []string, issue types.Issue) textRange {
+	// line 0 is about the file as a whole
+	if issue.LineNum < 1 || issue.LineNum > len(lines) {
+		return textRange{}
+	}
+	line := lines[issue.LineNum-1]
+	start, end := 0, len(line)
+	if issue.Column > 0 {
+		start, end = issue.Column-1, issue.EndColumn-1
+	}
+	return textRange{
+		Start: position{Line: issue.LineNum - 1, Character: utf16Column(line, start)},
+		End:   position{Line: issue.LineNum - 1, Character: utf16Column(line, end)},
+	}
+}
+
+// hover describes the key under the cursor: its type, what the schema says about it and its value,
+// masked when it looks like a secret
+func (s *Server) hover(params positionParams) any {
+	uri := params.TextDocument.URI
+	if _, ok := s.docs[uri]; !ok {
+		return nil
+	}
+	envVarMap, _, _ := s.check(uri)
+	line := params.Position.Line + 1
+
+	for key, envVar := range envVarMap {
+		if line < envVar.LineNum || line > envVar.EndLine {
+			continue
+		}
+		item, declared := s.schemaFor(uri)[key]
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "**%s**\n\n", key)
+		inferred := schema.InferType(key, envVar.Value)
+		switch {
+		case !declared:
+			fmt.Fprintf(&sb, "Type: `%s` (inferred, not in the schema)\n\n", inferred)
+		case item.Type != inferred:
+			fmt.Fprintf(&sb, "Type: `%s` (the value looks like `%s`)\n\n", item.Type, inferred)
+		default:
+			fmt.Fprintf(&sb, "Type: `%s`\n\n", item.Type)
+		}
+		if item.Description != "" {
+			sb.WriteString(item.Description + "\n\n")
+		}
+		if item.Required {
+			sb.WriteString("Required\n\n")
+		}
+		findings := s.options.Secrets.Findings(key, envVar.Value)
+		if len(findings) > 0 {
+			fmt.Fprintf(&sb, "Sensitive (%s, %s), value: `[SENSITIVE]`", findings[0].Detector, findings[0].Explanation)
+		} else if item.Sensitive {
+			sb.WriteString("Sensitive (marked in the schema), value: `[SENSITIVE]`")
+		} else if strings.Contains(envVar.Value, "\n") {
+			fmt.Fprintf(&sb, "Value: %d lines", strings.Count(envVar.Value, "\n")+1)
+		} else {
+			fmt.Fprintf(&sb, "Value: `%s`", envVar.Value)
+		}
+
+		result := hover{Contents: markupContent{Kind: "markdown", Value: sb.String()}}
+		if envVar.KeySpan.Column > 0 {
+			r := issueRange(splitLines(s.docs[uri]), types.Issue{LineNum: envVar.LineNum, Span: envVar.KeySpan})
+			result.Range = &r
+		}
+		return result
+	}
+	return nil
+}
+
+// complete offers the schema's keys that the document doesn't set yet, while the cursor is still on a key
+func (s *Server) complete(params positionParams) []completionItem {
+	items := []completionItem{}
+	uri := params.TextDocument.URI
+	lines := splitLines(s.docs[uri])
+	if params.Position.Line >= len(lines) {
+		return items
+	}
+	line := lines[params.Position.Line]
+	before := line[:byteColumn(line, params.Position.Character)]
+	if strings.Contains(before, "=") || strings.HasPrefix(strings.TrimSpace(before), "#") {
+		return items
+	}
+
+	envVarMap, _, _ := s.check(uri)
+	declared := s.schemaFor(uri)
+	keys := make([]string, 0, len(declared))
+	for key := range declared {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if envVar, ok := envVarMap[key]; ok && envVar.LineNum != params.Position.Line+1 {
+			continue
+		}
+		item := declared[key]
+		completion := completionItem{Label: key, Kind: completionKindVariable, Detail: item.Type, InsertText: key + "="}
+		if item.Required {
+			completion.Detail += ", required"
+		}
+		if item.Description != "" {
+			completion.Documentation = &markupContent{Kind: "markdown", Value: item.Description}
+		}
+		items = append(items, completion)
+	}
+	return items
+}
+
+// codeActions turns the suggested fixes of the issues in range into quick fixes
+func (s *Server) codeActions(params codeActionParams) []codeAction {
+	actions := []codeAction{}
+	uri := params.TextDocument.URI
+	text, ok := s.docs[uri]
+	if !ok {
+		return actions
+	}
+	_, errors, warnings := s.check(uri)
+	lines := splitLines(text)
+
+	from, to := params.Range.Start.Line+1, params.Range.End.Line+1
+	add := func(issues []types.Issue, severity int) {
+		for _, issue := range issues {
+			if issue.Fix == nil || issue.LineNum < from || issue.LineNum > to {
+				continue
+			}
+			actions = append(actions, codeAction{
+				Title:       issue.Fix.Description,
+				Kind:        "quickfix",
+				Diagnostics: []diagnostic{toDiagnostic(lines, issue, severity)},
+				Edit:        workspaceEdit{Changes: map[string][]textEdit{uri: {fixEdit(text, issue.Fix)}}},
+			})
+		}
+	}
+	add(errors, severityError)
+	add(warnings, severityWarning)
+	return actions
+}
+
+// fixEdit is the edit that replaces or removes the lines of a fix, keeping the document's line endings
+func fixEdit(text string, fix *types.Fix) textEdit {
+	lines := splitLines(text)
+	newline := "\n"
+	if strings.Contains(text, "\r\n") {
+		newline = "\r\n"
+	}
+
+	edit := textEdit{Range: textRange{Start: position{Line: fix.Line - 1}, End: position{Line: fix.EndLine}}}
+	if !fix.Delete {
+		edit.NewText = strings.ReplaceAll(fix.Text, "\n", newline) + newline
+	}
+
+	// the last line has no newline of its own to replace
+	if fix.EndLine == len(lines) {
+		last := lines[len(lines)-1]
+		edit.Range.End = position{Line: fix.EndLine - 1, Character: utf16Column(last, len(last))}
+		edit.NewText = strings.TrimSuffix(edit.NewText, newline)
+		if fix.Delete && fix.Line > 1 {
+			prev := lines[fix.Line-2]
+			edit.Range.Start = position{Line: fix.Line - 2, Character: utf16Column(prev, len(prev))}
+		}
+	}
+	return edit
+}
+
+// schemaFor loads the schema that applies to a document, nil when there isn't one.
+// It's read on every request so edits to the schema show up without restarting the server
+func (s *Server) schemaFor(uri string) types.Schema {
+	candidates := []string{s.options.Schema}
+	if s.options.Schema == "" {
+		u, err := url.Parse(uri)
+		if err != nil || u.Scheme != "file" {
+			return nil
+		}
+		dir := filepath.Dir(filepath.FromSlash(u.Path))
+		candidates = []string{filepath.Join(dir, "schema.json"), filepath.Join(dir, "schema.yaml"), filepath.Join(dir, "schema.yml")}
+	}
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if loaded, err := schema.Load(path); err == nil {
+			return loaded
+		}
+	}
+	return nil
+}
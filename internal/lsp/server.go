@@ -0,0 +1,199 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/secrets"
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// Options are the settings the server checks documents with
+type Options struct {
+	Config types.Config
+	Schema string // schema file for hover and completion, "" looks for schema.json or schema.yaml next to each document
+	// decides which values hover hides, nil uses the built-in rules
+	Secrets *secrets.Detector
+}
+
+// Server answers one editor over a pair of streams, usually stdin and stdout.
+// Requests are handled one at a time in the order they arrive
+type Server struct {
+	in      *textproto.Reader
+	out     io.Writer
+	options Options
+
+	docs        map[string]string // open documents by URI
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer returns a server reading requests from in and writing responses to out
+func NewServer(in io.Reader, out io.Writer, options Options) *Server {
+	if options.Secrets == nil {
+		options.Secrets = secrets.Default()
+	}
+	return &Server{
+		in:      textproto.NewReader(bufio.NewReader(in)),
+		out:     out,
+		options: options,
+		docs:    map[string]string{},
+	}
+}
+
+// ErrNoShutdown is returned by Run when the client exits without asking the server to shut down first
+var ErrNoShutdown = errors.New("exit without shutdown")
+
+// Run serves requests until the client sends exit or closes the input
+func (s *Server) Run() error {
+	for {
+		body, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.writeError(nil, codeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrNoShutdown
+			}
+			return nil
+		}
+		if err := s.handle(req); err != nil {
+			return err
+		}
+	}
+}
+
+// read returns the body of the next message, framed by a Content-Length header
+func (s *Server) read() ([]byte, error) {
+	header, err := s.in.ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("bad Content-Length header: %v", err)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in.R, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *Server) write(message any) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func (s *Server) reply(id *json.RawMessage, result any) error {
+	return s.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) writeError(id *json.RawMessage, code int, message string) error {
+	return s.write(errorResponse{JSONRPC: "2.0", ID: id, Error: rpcError{Code: code, Message: message}})
+}
+
+func (s *Server) notify(method string, params any) error {
+	return s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// handle runs one request or notification. Only failures to write are returned,
+// anything wrong with the request itself goes back to the client
+func (s *Server) handle(req request) error {
+	isRequest := req.ID != nil
+	if !s.initialized && req.Method != "initialize" {
+		if isRequest {
+			return s.writeError(req.ID, codeNotInitialized, "server not initialized")
+		}
+		return nil
+	}
+
+	switch req.Method {
+	case "initialize":
+		s.initialized = true
+		return s.reply(req.ID, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   1, // the whole document on every change
+				"hoverProvider":      true,
+				"completionProvider": map[string]any{},
+				"codeActionProvider": map[string]any{"codeActionKinds": []string{"quickfix"}},
+			},
+			"serverInfo": map[string]string{"name": "envdoc"},
+		})
+	case "shutdown":
+		s.shutdown = true
+		return s.reply(req.ID, nil)
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if json.Unmarshal(req.Params, &params) != nil {
+			return nil
+		}
+		s.docs[params.TextDocument.URI] = params.TextDocument.Text
+		return s.publish(params.TextDocument.URI)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if json.Unmarshal(req.Params, &params) != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		s.docs[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		return s.publish(params.TextDocument.URI)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if json.Unmarshal(req.Params, &params) != nil {
+			return nil
+		}
+		delete(s.docs, params.TextDocument.URI)
+		// leave nothing behind in the problems panel
+		return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []diagnostic{}})
+
+	case "textDocument/hover":
+		var params positionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.writeError(req.ID, codeInvalidParams, err.Error())
+		}
+		return s.reply(req.ID, s.hover(params))
+	case "textDocument/completion":
+		var params positionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.writeError(req.ID, codeInvalidParams, err.Error())
+		}
+		return s.reply(req.ID, s.complete(params))
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.writeError(req.ID, codeInvalidParams, err.Error())
+		}
+		return s.reply(req.ID, s.codeActions(params))
+	}
+
+	// notifications we don't care about ($/cancelRequest, didSave, ...) are dropped
+	if isRequest && !strings.HasPrefix(req.Method, "$/") {
+		return s.writeError(req.ID, codeMethodNotFound, "method not supported: "+req.Method)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// message is anything the server writes: a response, an error or a notification
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// script frames requests and notifications the way an editor would send them, ids count up from 1
+type script struct {
+	bytes.Buffer
+	next int
+}
+
+func (s *script) request(method string, params any) int {
+	s.next++
+	s.send(s.next, method, params)
+	return s.next
+}
+
+func (s *script) notify(method string, params any) {
+	s.send(0, method, params)
+}
+
+func (s *script) send(id int, method string, params any) {
+	msg := map[string]any{"jsonrpc": "2.0", "method": method}
+	if id != 0 {
+		msg["id"] = id
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+	body, _ := json.Marshal(msg)
+	fmt.Fprintf(&s.Buffer, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+// session is what the server answered, responses by id and notifications in order
+type session struct {
+	responses     map[int]message
+	notifications []message
+}
+
+func run(t *testing.T, in *script, options Options) (session, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := NewServer(in, &out, options).Run()
+
+	s := session{responses: map[int]message{}}
+	reader := textproto.NewReader(bufio.NewReader(&out))
+	for {
+		header, herr := reader.ReadMIMEHeader()
+		if herr == io.EOF {
+			break
+		}
+		if herr != nil {
+			t.Fatalf("bad header from server: %v", herr)
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, rerr := io.ReadFull(reader.R, body); rerr != nil {
+			t.Fatalf("short body from server: %v", rerr)
+		}
+		var msg message
+		if jerr := json.Unmarshal(body, &msg); jerr != nil {
+			t.Fatalf("server wrote invalid JSON %s: %v", body, jerr)
+		}
+		if msg.ID != nil {
+			s.responses[*msg.ID] = msg
+		} else {
+			s.notifications = append(s.notifications, msg)
+		}
+	}
+	return s, err
+}
+
+func (s session) result(t *testing.T, id int, v any) {
+	t.Helper()
+	msg, ok := s.responses[id]
+	if !ok {
+		t.Fatalf("no response to request %d", id)
+	}
+	if msg.Error != nil {
+		t.Fatalf("request %d failed: %s", id, msg.Error.Message)
+	}
+	if err := json.Unmarshal(msg.Result, v); err != nil {
+		t.Fatalf("response to request %d: %v", id, err)
+	}
+}
+
+const (
+	docURI = "file:///project/.env"
+	// the duplicate is on the last line, which has no newline of its own
+	docText = "DB_HOST=localhost\nAPI_KEY=sk_live_0123456789abcdefghij\nPORT=3000\nPORT=8080"
+)
+
+func TestServerSession(t *testing.T) {
+	schemaPath := filepath.Join(t.TempDir(), "schema.json")
+	schemaJSON := `{
+		"DB_HOST": {"Type": "string"},
+		"DB_NAME": {"Type": "string", "Required": true, "Description": "database to connect to"},
+		"API_KEY": {"Type": "string"}
+	}`
+	if err := os.WriteFile(schemaPath, []byte(schemaJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	in := &script{}
+	initID := in.request("initialize", map[string]any{"capabilities": map[string]any{}})
+	in.notify("initialized", map[string]any{})
+	in.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": docURI, "languageId": "dotenv", "version": 1, "text": docText},
+	})
+	hoverID := in.request("textDocument/hover", map[string]any{
+		"textDocument": map[string]any{"uri": docURI},
+		"position":     map[string]any{"line": 1, "character": 2},
+	})
+	in.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": "file:///project/new.env", "languageId": "dotenv", "version": 1, "text": "DB_"},
+	})
+	completionID := in.request("textDocument/completion", map[string]any{
+		"textDocument": map[string]any{"uri": "file:///project/new.env"},
+		"position":     map[string]any{"line": 0, "character": 3},
+	})
+	actionID := in.request("textDocument/codeAction", map[string]any{
+		"textDocument": map[string]any{"uri": docURI},
+		"range":        map[string]any{"start": map[string]any{"line": 3, "character": 0}, "end": map[string]any{"line": 3, "character": 0}},
+		"context":      map[string]any{"diagnostics": []any{}},
+	})
+	shutdownID := in.request("shutdown", nil)
+	in.notify("exit", nil)
+
+	s, err := run(t, in, Options{Config: types.Config{Expand: true}, Schema: schemaPath})
+	if err != nil {
+		t.Fatalf("Run returned %v after shutdown and exit", err)
+	}
+
+	t.Run("initialize", func(t *testing.T) {
+		var result struct {
+			Capabilities map[string]any `json:"capabilities"`
+		}
+		s.result(t, initID, &result)
+		for _, capability := range []string{"textDocumentSync", "hoverProvider", "completionProvider", "codeActionProvider"} {
+			if _, ok := result.Capabilities[capability]; !ok {
+				t.Errorf("initialize doesn't advertise %s", capability)
+			}
+		}
+	})
+
+	t.Run("diagnostics", func(t *testing.T) {
+		var params publishDiagnosticsParams
+		for _, n := range s.notifications {
+			if n.Method == "textDocument/publishDiagnostics" && strings.Contains(string(n.Params), docURI) {
+				if err := json.Unmarshal(n.Params, &params); err != nil {
+					t.Fatal(err)
+				}
+				break
+			}
+		}
+		if params.URI != docURI {
+			t.Fatalf("no diagnostics published for %s", docURI)
+		}
+		want := diagnostic{
+			Range:    textRange{Start: position{Line: 3, Character: 0}, End: position{Line: 3, Character: 4}},
+			Severity: severityWarning,
+			Code:     "ED006",
+			Source:   "envdoc",
+		}
+		for _, d := range params.Diagnostics {
+			if d.Code == want.Code {
+				d.Message = ""
+				if d != want {
+					t.Errorf("ED006 diagnostic = %+v, want %+v", d, want)
+				}
+				return
+			}
+		}
+		t.Errorf("no ED006 diagnostic in %+v", params.Diagnostics)
+	})
+
+	t.Run("hover masks secrets", func(t *testing.T) {
+		var result hover
+		s.result(t, hoverID, &result)
+		if !strings.Contains(result.Contents.Value, "**API_KEY**") {
+			t.Errorf("hover is not about API_KEY: %q", result.Contents.Value)
+		}
+		if !strings.Contains(result.Contents.Value, "`[SENSITIVE]`") {
+			t.Errorf("hover doesn't mask the value: %q", result.Contents.Value)
+		}
+		if strings.Contains(result.Contents.Value, "sk_live_") {
+			t.Errorf("hover leaks the value: %q", result.Contents.Value)
+		}
+	})
+
+	t.Run("completion offers schema keys", func(t *testing.T) {
+		var items []completionItem
+		s.result(t, completionID, &items)
+		var labels []string
+		for _, item := range items {
+			labels = append(labels, item.Label)
+			if item.Label == "DB_NAME" && (item.Detail != "string, required" || item.InsertText != "DB_NAME=") {
+				t.Errorf("DB_NAME completion = %+v", item)
+			}
+		}
+		if got, want := strings.Join(labels, ","), "API_KEY,DB_HOST,DB_NAME"; got != want {
+			t.Errorf("completion labels = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("code action on the last line", func(t *testing.T) {
+		var actions []codeAction
+		s.result(t, actionID, &actions)
+		if len(actions) != 1 {
+			t.Fatalf("got %d code actions, want 1: %+v", len(actions), actions)
+		}
+		edits := actions[0].Edit.Changes[docURI]
+		if len(edits) != 1 {
+			t.Fatalf("got %d edits, want 1", len(edits))
+		}
+		// removing the last line takes the newline before it, there's none after it to take
+		want := textEdit{Range: textRange{Start: position{Line: 2, Character: 9}, End: position{Line: 3, Character: 9}}}
+		if edits[0] != want {
+			t.Errorf("edit = %+v, want %+v", edits[0], want)
+		}
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		if msg, ok := s.responses[shutdownID]; !ok || msg.Error != nil {
+			t.Errorf("shutdown wasn't answered: %+v", msg)
+		}
+	})
+}
+
+func TestServerExitWithoutShutdown(t *testing.T) {
+	in := &script{}
+	in.request("initialize", map[string]any{"capabilities": map[string]any{}})
+	in.notify("exit", nil)
+
+	if _, err := run(t, in, Options{}); !errors.Is(err, ErrNoShutdown) {
+		t.Errorf("Run = %v, want ErrNoShutdown", err)
+	}
+}
+
+func TestServerNotInitialized(t *testing.T) {
+	in := &script{}
+	id := in.request("textDocument/hover", map[string]any{
+		"textDocument": map[string]any{"uri": docURI},
+		"position":     map[string]any{"line": 0, "character": 0},
+	})
+
+	s, err := run(t, in, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := s.responses[id]; msg.Error == nil || msg.Error.Code != codeNotInitialized {
+		t.Errorf("hover before initialize = %+v, want a not initialized error", msg)
+	}
+}
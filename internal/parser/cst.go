@@ -0,0 +1,287 @@
+package parser
+
+import (
+	"io"
+	"strings"
+)
+
+// Node is one piece of a .env document. Printing the Raw text of every node in order
+// gives back the original file byte for byte
+type Node interface {
+	Raw() string
+	Line() int
+}
+
+// Document is the concrete syntax tree of a .env file
+type Document struct {
+	Nodes []Node
+}
+
+// Blank is an empty or whitespace-only line
+type Blank struct {
+	Text    string
+	Newline string // "\n", "\r\n" or "" on a last line without one
+	LineNum int
+}
+
+// Comment is a full-line comment, Text starts at the '#'
+type Comment struct {
+	Indent  string
+	Text    string
+	Newline string
+	LineNum int
+}
+
+// Assignment is a KEY=value line, split so every byte of the source lands in exactly one field
+type Assignment struct {
+	Indent   string // whitespace before the key
+	Export   string // "export" and the whitespace after it, empty when absent
+	Key      string
+	BeforeEq string // whitespace between the key and '='
+	AfterEq  string // whitespace between '=' and the value
+	Value    string // raw value, quotes included
+	Quote    byte   // '"' or '\'' for quoted values, 0 otherwise
+	Closed   bool   // whether a quoted value found its closing quote
+	Trailing string // whitespace, inline comment or stray content after the value
+	Newline  string
+	LineNum  int
+	EndLine  int // last line of a value spanning several lines, LineNum otherwise
+}
+
+// Trivia is a line we couldn't make sense of (no '=' for instance), kept as-is
+type Trivia struct {
+	Text    string
+	Newline string
+	LineNum int
+}
+
+func (b *Blank) Raw() string { return b.Text + b.Newline }
+func (b *Blank) Line() int   { return b.LineNum }
+
+func (c *Comment) Raw() string { return c.Indent + c.Text + c.Newline }
+func (c *Comment) Line() int   { return c.LineNum }
+
+func (a *Assignment) Raw() string {
+	return a.Indent + a.Export + a.Key + a.BeforeEq + "=" + a.AfterEq + a.Value + a.Trailing + a.Newline
+}
+func (a *Assignment) Line() int { return a.LineNum }
+
+func (t *Trivia) Raw() string { return t.Text + t.Newline }
+func (t *Trivia) Line() int   { return t.LineNum }
+
+// ParseDocument builds the concrete syntax tree for src, splitting values where the dialect's
+// loader would: export prefixes, inline comments, heredocs and values running onto more lines
+// follow the same rules as Parse. It never fails, anything it can't classify becomes Trivia
+func ParseDocument(src string, dialect Dialect) *Document {
+	var lines []rawLine
+	for len(src) > 0 {
+		text, newline, rest := cutLine(src)
+		lines = append(lines, rawLine{text: text, newline: newline})
+		src = rest
+	}
+
+	doc := &Document{}
+	for i := 0; i < len(lines); i++ {
+		node := parseNode(lines[i].text, lines[i].newline, i+1, dialect)
+		if a, ok := node.(*Assignment); ok {
+			i += extendAssignment(a, lines[i+1:], dialect)
+		}
+		doc.Nodes = append(doc.Nodes, node)
+	}
+
+	return doc
+}
+
+// Print writes the document back out
+func Print(w io.Writer, doc *Document) error {
+	for _, node := range doc.Nodes {
+		if _, err := io.WriteString(w, node.Raw()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns the printed document
+func (d *Document) String() string {
+	var sb strings.Builder
+	Print(&sb, d)
+	return sb.String()
+}
+
+// Assignments returns the assignment nodes in file order
+func (d *Document) Assignments() []*Assignment {
+	var assignments []*Assignment
+	for _, node := range d.Nodes {
+		if a, ok := node.(*Assignment); ok {
+			assignments = append(assignments, a)
+		}
+	}
+	return assignments
+}
+
+type rawLine struct {
+	text    string
+	newline string
+}
+
+// cutLine splits off the first line of src, returning the line without its terminator,
+// the terminator itself and the remaining text
+func cutLine(src string) (string, string, string) {
+	idx := strings.IndexByte(src, '\n')
+	if idx == -1 {
+		return src, "", ""
+	}
+	line, rest := src[:idx], src[idx+1:]
+	if strings.HasSuffix(line, "\r") {
+		return line[:len(line)-1], "\r\n", rest
+	}
+	return line, "\n", rest
+}
+
+func parseNode(line, newline string, lineNum int, dialect Dialect) Node {
+	body := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(body)]
+
+	if strings.TrimSpace(body) == "" {
+		return &Blank{Text: line, Newline: newline, LineNum: lineNum}
+	}
+	if body[0] == '#' {
+		return &Comment{Indent: indent, Text: body, Newline: newline, LineNum: lineNum}
+	}
+
+	eq := strings.IndexByte(body, '=')
+	if eq == -1 {
+		return &Trivia{Text: line, Newline: newline, LineNum: lineNum}
+	}
+
+	a := &Assignment{Indent: indent, Newline: newline, LineNum: lineNum, EndLine: lineNum}
+
+	keyPart := body[:eq]
+	if export, ok := cutExport(keyPart); ok && dialect.Export() {
+		a.Export = keyPart[:len(keyPart)-len(export)]
+		keyPart = export
+	}
+	a.Key = strings.TrimRight(keyPart, " \t")
+	a.BeforeEq = keyPart[len(a.Key):]
+
+	valuePart := body[eq+1:]
+	rest := strings.TrimLeft(valuePart, " \t")
+	a.AfterEq = valuePart[:len(valuePart)-len(rest)]
+
+	splitValue(a, rest, dialect)
+	return a
+}
+
+// cutExport strips a leading "export " from the key part, only when a key follows it
+func cutExport(keyPart string) (string, bool) {
+	after, ok := strings.CutPrefix(keyPart, "export ")
+	if !ok {
+		return keyPart, false
+	}
+	key := strings.TrimLeft(after, " \t")
+	if strings.TrimSpace(key) == "" {
+		return keyPart, false
+	}
+	return key, true
+}
+
+// splitValue separates the value from whatever trails it on the line
+func splitValue(a *Assignment, rest string, dialect Dialect) {
+	if len(rest) > 0 && (rest[0] == '"' || rest[0] == '\'') {
+		a.Quote = rest[0]
+		if end := closingQuote(rest, rest[0]); end != -1 {
+			a.Value = rest[:end+1]
+			a.Trailing = rest[end+1:]
+			a.Closed = true
+			return
+		}
+		a.Value = rest
+		return
+	}
+
+	// unquoted values run until an inline comment, the space before it is trailing, and
+	// for loaders that stop at whitespace what follows the first space is too
+	value := rest
+	if idx := dialect.InlineComment(value); idx != -1 {
+		value = value[:idx]
+	}
+	value = strings.TrimRight(value, " \t")
+	if !dialect.UnquotedSpaces() {
+		if idx := unquotedSpace(value); idx != -1 {
+			value = value[:idx]
+		}
+	}
+	a.Value = value
+	a.Trailing = rest[len(value):]
+}
+
+// extendAssignment pulls the following lines into a value that continues past its first line,
+// the same way ParseFile does, and returns how many lines it took
+func extendAssignment(a *Assignment, lines []rawLine, dialect Dialect) int {
+	if a.Quote != 0 && !a.Closed {
+		if !dialect.Multiline(a.Quote) {
+			return 0
+		}
+		return extendQuoted(a, lines)
+	}
+	if !dialect.Heredoc() || strings.TrimSpace(a.Trailing) != "" {
+		return 0
+	}
+	marker, ok := heredocMarker(a.Value)
+	if !ok {
+		return 0
+	}
+
+	for n, line := range lines {
+		if strings.TrimSpace(line.text) != marker {
+			continue
+		}
+		var sb strings.Builder
+		sb.WriteString(a.Value + a.Trailing + a.Newline)
+		for _, body := range lines[:n] {
+			sb.WriteString(body.text + body.newline)
+		}
+		sb.WriteString(line.text)
+		a.Value, a.Trailing, a.Newline = sb.String(), "", line.newline
+		a.EndLine = a.LineNum + n + 1
+		return n + 1
+	}
+	return 0
+}
+
+// extendQuoted joins lines onto an unclosed quoted value until its closing quote. When the
+// quote never closes the value is left on its own line
+func extendQuoted(a *Assignment, lines []rawLine) int {
+	value, newline := a.Value, a.Newline
+	i := 1
+	for n, line := range lines {
+		value += newline + line.text
+		newline = line.newline
+		for ; i < len(value); i++ {
+			switch value[i] {
+			case '\\':
+				i++
+			case a.Quote:
+				a.Value, a.Trailing, a.Newline = value[:i+1], value[i+1:], newline
+				a.Closed = true
+				a.EndLine = a.LineNum + n + 1
+				return n + 1
+			}
+		}
+	}
+	return 0
+}
+
+// closingQuote returns the index of the unescaped quote closing s[0], or -1
+func closingQuote(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// every file under testdata/roundtrip has to print back byte for byte, whatever the dialect
+func TestParseDocumentRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "roundtrip", "*.env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no files in testdata/roundtrip")
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := string(data)
+		for _, name := range DialectNames() {
+			t.Run(filepath.Base(file)+"/"+name, func(t *testing.T) {
+				if got := ParseDocument(src, dialectOf(name)).String(); got != src {
+					t.Errorf("round trip changed the file\n got: %q\nwant: %q", got, src)
+				}
+			})
+		}
+	}
+}
+
+// values spanning several lines have to end up in one assignment, not as trivia after it
+func TestParseDocumentMultiline(t *testing.T) {
+	tests := []struct {
+		file    string
+		key     string
+		endLine int
+		closed  bool
+	}{
+		{"crlf.env", "MULTI", 7, true},
+		{"heredoc.env", "SCRIPT", 7, false},
+		{"heredoc.env", "QUOTED_MARKER", 10, false},
+		{"heredoc.env", "DASHED", 13, false},
+		{"heredoc.env", "NEVER_ENDS", 14, false},
+		{"pem.env", "PRIVATE_KEY", 5, true},
+		{"pem.env", "CERT", 8, true},
+		{"unclosed.env", "BROKEN", 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file+"/"+tt.key, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "roundtrip", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var found *Assignment
+			for _, a := range ParseDocument(string(data), dialectOf(DefaultDialect)).Assignments() {
+				if a.Key == tt.key {
+					found = a
+				}
+			}
+			if found == nil {
+				t.Fatalf("no assignment for %s", tt.key)
+			}
+			if found.EndLine != tt.endLine {
+				t.Errorf("EndLine = %d, want %d", found.EndLine, tt.endLine)
+			}
+			if found.Quote != 0 && found.Closed != tt.closed {
+				t.Errorf("Closed = %v, want %v", found.Closed, tt.closed)
+			}
+		})
+	}
+}
+
+// the document has to split values where Parse does for the same dialect, or fmt, fix and
+// example would rewrite a value validate reads differently
+func TestParseDocumentDialects(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		line     string
+		key      string
+		export   string
+		value    string
+		trailing string
+	}{
+		{"envdoc", "COLOR=#fff", "COLOR", "", "", "#fff"},
+		{"docker-compose", "COLOR=#fff", "COLOR", "", "#fff", ""},
+		{"python-dotenv", "ANCHOR=page#top # comment", "ANCHOR", "", "page#top", " # comment"},
+		{"node", "ANCHOR=page#top", "ANCHOR", "", "page", "#top"},
+		{"systemd", "SPACED=value # not a comment", "SPACED", "", "value # not a comment", ""},
+		{"shell", "GREETING=hello world # hi", "GREETING", "", "hello", " world # hi"},
+		{"docker-compose", "GREETING=hello world", "GREETING", "", "hello world", ""},
+
+		// envdoc and systemd don't know export, the key is the whole thing
+		{"envdoc", "export EXPORTED=yes", "export EXPORTED", "", "yes", ""},
+		{"systemd", "export EXPORTED=yes", "export EXPORTED", "", "yes", ""},
+		{"docker-compose", "export  EXPORTED=yes", "EXPORTED", "export  ", "yes", ""},
+		{"shell", "export\tTABBED=no", "export\tTABBED", "", "no", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect+"/"+tt.line, func(t *testing.T) {
+			d := dialectOf(tt.dialect)
+			assignments := ParseDocument(tt.line, d).Assignments()
+			if len(assignments) != 1 {
+				t.Fatalf("got %d assignments, want 1", len(assignments))
+			}
+			a := assignments[0]
+			if a.Key != tt.key || a.Export != tt.export || a.Value != tt.value || a.Trailing != tt.trailing {
+				t.Errorf("got key %q export %q value %q trailing %q, want %q %q %q %q",
+					a.Key, a.Export, a.Value, a.Trailing, tt.key, tt.export, tt.value, tt.trailing)
+			}
+
+			// and the unquoted value is what Parse loads
+			vars, _, _, err := Parse(strings.NewReader(tt.line), types.Config{Dialect: tt.dialect})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := vars[strings.TrimSpace(tt.key)].Value; got != a.Value {
+				t.Errorf("Parse reads %q, the document has %q", got, a.Value)
+			}
+		})
+	}
+}
+
+// heredocs are only joined for the dialects that understand them
+func TestParseDocumentHeredocDialect(t *testing.T) {
+	src := "SCRIPT=<<EOF\nline\nEOF\n"
+	if n := len(ParseDocument(src, dialectOf("envdoc")).Nodes); n != 1 {
+		t.Errorf("envdoc: got %d nodes, want the heredoc as 1", n)
+	}
+	if n := len(ParseDocument(src, dialectOf("docker-compose")).Nodes); n != 3 {
+		t.Errorf("docker-compose: got %d nodes, want 3 separate lines", n)
+	}
+}
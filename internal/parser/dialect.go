@@ -0,0 +1,230 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExpandMode says which ${VAR} references a loader resolves
+type ExpandMode int
+
+const (
+	ExpandNone   ExpandMode = iota // values are taken literally
+	ExpandBraced                   // only ${VAR} forms, a bare $VAR stays as written
+	ExpandFull                     // ${VAR} and $VAR
+)
+
+// Dialect captures the rules one loader uses to read a .env file. ParseFile consults it
+// wherever loaders disagree: export prefixes, inline comments, escapes and expansion
+type Dialect interface {
+	Name() string
+	// Export reports whether a leading "export " is dropped from the key
+	Export() bool
+	// InlineComment returns where an inline comment starts in an unquoted value, or -1
+	InlineComment(value string) int
+	// Unescape turns the text between the quotes into the value the loader sees
+	Unescape(inner string, quote byte) string
+	// Expansion reports how references are resolved in a value with this quote (0 for unquoted)
+	Expansion(quote byte) ExpandMode
+	// Multiline reports whether a value with this quote can run onto the following lines
+	Multiline(quote byte) bool
+	// Heredoc reports whether KEY=<<EOF blocks are understood
+	Heredoc() bool
+	// UnquotedSpaces reports whether an unquoted value may contain whitespace
+	UnquotedSpaces() bool
+}
+
+// DefaultDialect is the set of rules envdoc has always used
+const DefaultDialect = "envdoc"
+
+var dialects = map[string]Dialect{}
+
+func init() {
+	for _, d := range []Dialect{
+		envdocDialect{},
+		composeDialect{},
+		systemdDialect{},
+		pythonDialect{},
+		nodeDialect{},
+		shellDialect{},
+	} {
+		dialects[d.Name()] = d
+	}
+}
+
+// LookupDialect returns the dialect registered under name, an empty name means the default
+func LookupDialect(name string) (Dialect, error) {
+	if name == "" {
+		name = DefaultDialect
+	}
+	d, ok := dialects[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown dialect %q (use %s)", name, strings.Join(DialectNames(), ", "))
+	}
+	return d, nil
+}
+
+// DialectNames lists the registered dialects in alphabetical order
+func DialectNames() []string {
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dialectOf is LookupDialect for code past ParseFile's check, falling back to the default
+func dialectOf(name string) Dialect {
+	d, err := LookupDialect(name)
+	if err != nil {
+		return envdocDialect{}
+	}
+	return d
+}
+
+// envdoc: values are kept raw, '#' starts a comment anywhere in an unquoted value
+type envdocDialect struct{}
+
+func (envdocDialect) Name() string                         { return DefaultDialect }
+func (envdocDialect) Export() bool                         { return false }
+func (envdocDialect) InlineComment(value string) int       { return strings.IndexByte(value, '#') }
+func (envdocDialect) Unescape(inner string, _ byte) string { return inner }
+func (envdocDialect) Multiline(byte) bool                  { return true }
+func (envdocDialect) Heredoc() bool                        { return true }
+func (envdocDialect) UnquotedSpaces() bool                 { return true }
+func (envdocDialect) Expansion(quote byte) ExpandMode      { return expandUnlessSingle(quote, ExpandFull) }
+
+// docker-compose env_file: inline comments need a space before the '#', double quotes take
+// \n \r \t \\ escapes, single quotes are literal
+type composeDialect struct{}
+
+func (composeDialect) Name() string                   { return "docker-compose" }
+func (composeDialect) Export() bool                   { return true }
+func (composeDialect) InlineComment(value string) int { return spacedComment(value) }
+func (composeDialect) Multiline(byte) bool            { return true }
+func (composeDialect) Heredoc() bool                  { return false }
+func (composeDialect) UnquotedSpaces() bool           { return true }
+func (composeDialect) Expansion(quote byte) ExpandMode {
+	return expandUnlessSingle(quote, ExpandFull)
+}
+func (composeDialect) Unescape(inner string, quote byte) string {
+	if quote != '"' {
+		return inner
+	}
+	return unescape(inner, map[byte]string{'n': "\n", 'r': "\r", 't': "\t", '\\': "\\", '"': "\""})
+}
+
+// systemd EnvironmentFile: no export, no inline comments, no expansion
+type systemdDialect struct{}
+
+func (systemdDialect) Name() string              { return "systemd" }
+func (systemdDialect) Export() bool              { return false }
+func (systemdDialect) InlineComment(string) int  { return -1 }
+func (systemdDialect) Multiline(byte) bool       { return true }
+func (systemdDialect) Heredoc() bool             { return false }
+func (systemdDialect) UnquotedSpaces() bool      { return true }
+func (systemdDialect) Expansion(byte) ExpandMode { return ExpandNone }
+func (systemdDialect) Unescape(inner string, quote byte) string {
+	if quote != '"' {
+		return inner
+	}
+	return unescape(inner, map[byte]string{'"': "\"", '\\': "\\", '`': "`", '$': "$", '\n': ""})
+}
+
+// python-dotenv: only ${VAR} is expanded, double quotes take the usual C escapes and
+// single quotes only \\ and \'
+type pythonDialect struct{}
+
+func (pythonDialect) Name() string                   { return "python-dotenv" }
+func (pythonDialect) Export() bool                   { return true }
+func (pythonDialect) InlineComment(value string) int { return spacedComment(value) }
+func (pythonDialect) Multiline(byte) bool            { return true }
+func (pythonDialect) Heredoc() bool                  { return false }
+func (pythonDialect) UnquotedSpaces() bool           { return true }
+func (pythonDialect) Expansion(quote byte) ExpandMode {
+	return expandUnlessSingle(quote, ExpandBraced)
+}
+func (pythonDialect) Unescape(inner string, quote byte) string {
+	if quote == '\'' {
+		return unescape(inner, map[byte]string{'\\': "\\", '\'': "'"})
+	}
+	return unescape(inner, map[byte]string{
+		'\\': "\\", '\'': "'", '"': "\"", 'a': "\a", 'b': "\b",
+		'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v",
+	})
+}
+
+// node dotenv: '#' ends an unquoted value anywhere, \n is the only escape and nothing is
+// expanded (that's dotenv-expand's job)
+type nodeDialect struct{}
+
+func (nodeDialect) Name() string                   { return "node" }
+func (nodeDialect) Export() bool                   { return true }
+func (nodeDialect) InlineComment(value string) int { return strings.IndexByte(value, '#') }
+func (nodeDialect) Multiline(byte) bool            { return true }
+func (nodeDialect) Heredoc() bool                  { return false }
+func (nodeDialect) UnquotedSpaces() bool           { return true }
+func (nodeDialect) Expansion(byte) ExpandMode      { return ExpandNone }
+func (nodeDialect) Unescape(inner string, quote byte) string {
+	if quote != '"' {
+		return inner
+	}
+	return strings.NewReplacer(`\n`, "\n", `\r`, "\r").Replace(inner)
+}
+
+// shell: the file is sourced by sh, so an unquoted space ends the assignment
+type shellDialect struct{}
+
+func (shellDialect) Name() string                   { return "shell" }
+func (shellDialect) Export() bool                   { return true }
+func (shellDialect) InlineComment(value string) int { return spacedComment(value) }
+func (shellDialect) Multiline(byte) bool            { return true }
+func (shellDialect) Heredoc() bool                  { return false }
+func (shellDialect) UnquotedSpaces() bool           { return false }
+func (shellDialect) Expansion(quote byte) ExpandMode {
+	return expandUnlessSingle(quote, ExpandFull)
+}
+func (shellDialect) Unescape(inner string, quote byte) string {
+	if quote != '"' {
+		return inner
+	}
+	return unescape(inner, map[byte]string{'\\': "\\", '"': "\"", '$': "\\$", '`': "`", '\n': ""})
+}
+
+func expandUnlessSingle(quote byte, mode ExpandMode) ExpandMode {
+	if quote == '\'' {
+		return ExpandNone
+	}
+	return mode
+}
+
+// spacedComment finds a '#' right after whitespace, so KEY=#fff keeps its value
+func spacedComment(value string) int {
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape replaces the backslash sequences found in escapes, other backslashes are kept
+func unescape(s string, escapes map[byte]string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			if replacement, ok := escapes[s[i+1]]; ok {
+				sb.WriteString(replacement)
+				i++
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
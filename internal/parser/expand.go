@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/lint"
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// expandRules are the rules only Expand reports
+var expandRules = []string{"ED012", "ED013", "ED014", "ED017"}
+
+// Expand resolves $VAR and ${VAR} references in place, with the POSIX operators
+// ${VAR:-default}, ${VAR-default}, ${VAR:+alt}, ${VAR+alt}, ${VAR:?err} and ${VAR?err}.
+// Which values get expanded, and whether bare $VAR counts, is up to the config's dialect;
+// single-quoted values are always left alone and \$ gives a literal dollar sign.
+// By default undefined and malformed references are warnings, cycles and failed :? checks are errors
+func Expand(envVarMap types.EnvVarMap, config types.Config) ([]types.Issue, []types.Issue) {
+	e := &expander{
+		vars:    envVarMap,
+		config:  config,
+		dialect: dialectOf(config.Dialect),
+		state:   make(map[string]int, len(envVarMap)),
+	}
+
+	// sorted so cycles are always reported from the same key
+	keys := make([]string, 0, len(envVarMap))
+	for key := range envVarMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		e.resolve(key, nil)
+	}
+	return e.errors, e.warnings
+}
+
+const (
+	unresolved = iota
+	resolving
+	resolved
+)
+
+type expander struct {
+	vars     types.EnvVarMap
+	config   types.Config
+	dialect  Dialect
+	state    map[string]int
+	errors   []types.Issue
+	warnings []types.Issue
+}
+
+// resolve expands key's value once, path is the chain of keys being resolved above it
+func (e *expander) resolve(key string, path []string) {
+	switch e.state[key] {
+	case resolved:
+		return
+	case resolving:
+		cycle := append(slices.Clone(path[slices.Index(path, key):]), key)
+		lint.Add("ED014", types.Issue{
+			LineNum: e.vars[key].LineNum,
+			Message: fmt.Sprintf("variable references form a cycle: %s", strings.Join(cycle, " -> ")),
+			KeyName: key,
+			Span:    e.vars[key].ValueSpan,
+		}, e.config.Strict, &e.errors, &e.warnings)
+		return
+	}
+
+	envVar := e.vars[key]
+	e.state[key] = resolving
+	if mode := e.dialect.Expansion(envVar.Quote); mode != ExpandNone {
+		envVar.Value = e.expand(envVar.Value, key, mode, append(path, key))
+		e.vars[key] = envVar
+	}
+	e.state[key] = resolved
+}
+
+// lookup returns the resolved value of name and whether it is set at all
+func (e *expander) lookup(name string, path []string) (string, bool) {
+	if _, ok := e.vars[name]; ok {
+		// a key caught in a cycle stays unresolved, leave it empty rather than half expanded
+		if e.state[name] == resolving {
+			e.resolve(name, path)
+			return "", true
+		}
+		e.resolve(name, path)
+		return e.vars[name].Value, true
+	}
+	if e.config.ProcessEnv {
+		return os.LookupEnv(name)
+	}
+	return "", false
+}
+
+func (e *expander) expand(value, key string, mode ExpandMode, path []string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		if c == '\\' && i+1 < len(value) && value[i+1] == '$' {
+			sb.WriteByte('$')
+			i++
+			continue
+		}
+		if c != '$' || i+1 == len(value) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		// ${...} with an optional operator
+		if value[i+1] == '{' {
+			end := matchingBrace(value, i+1)
+			if end == -1 {
+				e.report("ED017", key, "unterminated ${ in value")
+				sb.WriteString(value[i:])
+				break
+			}
+			sb.WriteString(e.expandBraced(value[i+2:end], key, mode, path))
+			i = end
+			continue
+		}
+
+		// bare $NAME
+		n := nameLength(value[i+1:])
+		if n == 0 || mode == ExpandBraced {
+			sb.WriteByte(c)
+			continue
+		}
+		name := value[i+1 : i+1+n]
+		resolvedValue, ok := e.lookup(name, path)
+		if !ok {
+			e.report("ED012", key, fmt.Sprintf("undefined variable %s", name))
+		}
+		sb.WriteString(resolvedValue)
+		i += n
+	}
+
+	return sb.String()
+}
+
+// expandBraced handles the inside of ${...}
+func (e *expander) expandBraced(expr, key string, mode ExpandMode, path []string) string {
+	n := nameLength(expr)
+	if n == 0 {
+		e.report("ED017", key, fmt.Sprintf("invalid reference ${%s}", expr))
+		return ""
+	}
+	name, op := expr[:n], expr[n:]
+
+	value, set := e.lookup(name, path)
+	if op == "" {
+		if !set {
+			e.report("ED012", key, fmt.Sprintf("undefined variable %s", name))
+		}
+		return value
+	}
+
+	// with the colon, an empty value counts as unset
+	colon := strings.HasPrefix(op, ":")
+	if colon {
+		op = op[1:]
+	}
+	if op == "" {
+		e.report("ED017", key, fmt.Sprintf("invalid reference ${%s}", expr))
+		return value
+	}
+	word := op[1:]
+	usable := set && (!colon || value != "")
+
+	switch op[0] {
+	case '-':
+		if usable {
+			return value
+		}
+		return e.expand(word, key, mode, path)
+	case '+':
+		if usable {
+			return e.expand(word, key, mode, path)
+		}
+		return ""
+	case '?':
+		if usable {
+			return value
+		}
+		message := e.expand(word, key, mode, path)
+		if message == "" {
+			message = "parameter null or not set"
+		}
+		lint.Add("ED013", types.Issue{
+			LineNum: e.vars[key].LineNum,
+			Message: fmt.Sprintf("%s: %s", name, message),
+			KeyName: key,
+			Span:    e.vars[key].ValueSpan,
+		}, e.config.Strict, &e.errors, &e.warnings)
+		return ""
+	}
+
+	e.report("ED017", key, fmt.Sprintf("unsupported operator in ${%s}", expr))
+	return value
+}
+
+func (e *expander) report(rule, key, message string) {
+	lint.Add(rule, types.Issue{
+		LineNum: e.vars[key].LineNum,
+		Message: message,
+		KeyName: key,
+		Span:    e.vars[key].ValueSpan,
+	}, e.config.Strict, &e.errors, &e.warnings)
+}
+
+// matchingBrace returns the index of the '}' closing the '{' at open, nested ${} included
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// nameLength returns how many leading bytes of s form a variable name
+func nameLength(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		isLetter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !isLetter && (i == 0 || c < '0' || c > '9') {
+			return i
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/lint"
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// FormatOptions changes what Format does on top of the canonical layout
+type FormatOptions struct {
+	SortKeys bool // sort keys inside each section between comment lines
+}
+
+// Format returns src in canonical form: KEY=value with no spaces around '=', values quoted only
+// when they need it (double quotes preferred), one space before inline comments, single blank
+// lines between groups and one newline at the end. Lines it can't safely rewrite are kept as written.
+// The result is parsed again and rejected if any key or resolved value differs from src
+func Format(src string, config types.Config, opts FormatOptions) (string, error) {
+	dialect, err := LookupDialect(config.Dialect)
+	if err != nil {
+		return "", err
+	}
+
+	newline := "\n"
+	if _, nl, _ := cutLine(src); nl == "\r\n" {
+		newline = "\r\n"
+	}
+
+	doc := ParseDocument(src, dialect)
+	lines := make([]formatLine, 0, len(doc.Nodes))
+	for _, node := range doc.Nodes {
+		switch n := node.(type) {
+		case *Blank:
+			lines = append(lines, formatLine{blank: true})
+		case *Comment:
+			lines = append(lines, formatLine{text: strings.TrimRight(n.Text, " \t")})
+		case *Assignment:
+			lines = append(lines, formatLine{text: formatAssignment(n, dialect, config), key: n.Key, assignment: true})
+		default:
+			lines = append(lines, formatLine{text: strings.TrimRight(strings.TrimSuffix(node.Raw(), newlineOf(node)), " \t")})
+		}
+	}
+
+	if opts.SortKeys {
+		sortSections(lines)
+	}
+
+	var sb strings.Builder
+	pendingBlank := false
+	for _, line := range lines {
+		if line.blank {
+			// runs of blank lines collapse to one, and none at the top of the file
+			pendingBlank = sb.Len() > 0
+			continue
+		}
+		if pendingBlank {
+			sb.WriteString(newline)
+			pendingBlank = false
+		}
+		sb.WriteString(strings.ReplaceAll(line.text, "\n", newline) + newline)
+	}
+	out := sb.String()
+
+	if err := verifyFormat(src, out, config); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// formatLine is one node of the formatted document, multiline values hold their inner newlines as "\n"
+type formatLine struct {
+	text       string
+	key        string
+	blank      bool
+	assignment bool
+}
+
+func newlineOf(node Node) string {
+	switch n := node.(type) {
+	case *Blank:
+		return n.Newline
+	case *Comment:
+		return n.Newline
+	case *Assignment:
+		return n.Newline
+	case *Trivia:
+		return n.Newline
+	}
+	return ""
+}
+
+// formatAssignment rewrites one assignment, falling back to the source text whenever the
+// rewrite would read back differently under the dialect
+func formatAssignment(a *Assignment, dialect Dialect, config types.Config) string {
+	raw := strings.TrimSuffix(a.Raw(), a.Newline)
+	rawLines := splitRawLines(raw)
+	keep := strings.TrimLeft(strings.Join(rawLines, "\n"), " \t")
+
+	original, ok := parseLine(rawLines[0], 1, rawLines[1:], config)
+	if !ok || original.Key == "" || original.EndLine != len(rawLines) ||
+		original.Unclosed || original.DanglingEscape || original.SpaceCut || original.Trailing != "" || original.Heredoc != "" {
+		return keep
+	}
+
+	prefix := a.Export
+	if prefix != "" && dialect.Export() {
+		prefix = "export "
+	}
+	prefix += a.Key + "="
+
+	comment := inlineComment(a, original, dialect)
+
+	for _, value := range quoteCandidates(original.Value, original.Quote) {
+		line := prefix + value
+		if comment != "" {
+			line += " " + comment
+		}
+		if sameEntry(line, original, dialect, config) {
+			return line
+		}
+	}
+	return keep
+}
+
+// splitRawLines splits the source text of a node into lines the way the line scanner would
+func splitRawLines(raw string) []string {
+	lines := strings.Split(raw, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// inlineComment finds the comment after the value, if any, as the dialect reads it
+func inlineComment(a *Assignment, e lint.Entry, dialect Dialect) string {
+	if e.Quote != 0 {
+		return strings.TrimSpace(a.Trailing)
+	}
+	rest := strings.TrimSpace(a.Value + a.Trailing)
+	if idx := dialect.InlineComment(rest); idx != -1 {
+		return strings.TrimSpace(rest[idx:])
+	}
+	return ""
+}
+
+// quoteCandidates lists ways of writing value, most preferred first: bare, then double quoted.
+// An empty value keeps its quotes if it had them, KEY="" says the emptiness is on purpose
+func quoteCandidates(value string, quote byte) []string {
+	var candidates []string
+	if value == "" && quote == 0 {
+		candidates = append(candidates, "")
+	}
+	if value != "" && !strings.ContainsAny(value, " \t\n\r#\"'`") && !strings.HasSuffix(value, `\`) && !strings.HasPrefix(value, "<<") {
+		candidates = append(candidates, value)
+	}
+	candidates = append(candidates, `"`+value+`"`)
+	if strings.ContainsAny(value, `"\`) {
+		candidates = append(candidates, `"`+strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)+`"`)
+	}
+	return candidates
+}
+
+// sameEntry reports whether line reads back as the same key and value as original,
+// with references expanded the same way
+func sameEntry(line string, original lint.Entry, dialect Dialect, config types.Config) bool {
+	lines := strings.Split(line, "\n")
+	e, ok := parseLine(lines[0], 1, lines[1:], config)
+	if !ok || e.EndLine != len(lines) || e.Unclosed || e.DanglingEscape || e.Trailing != "" || e.Heredoc != "" {
+		return false
+	}
+	if e.Key != original.Key || e.Value != original.Value {
+		return false
+	}
+	if strings.Contains(e.Value, "$") && dialect.Expansion(e.Quote) != dialect.Expansion(original.Quote) {
+		return false
+	}
+	return true
+}
+
+// sortSections sorts the keys of each section, a section being everything between two
+// comment lines. Blank lines inside a section stay where they are and keys are sorted across
+// them, since a heading comment is what names a group. A key right under an
+// envdoc:disable-next-line comment stays put so the comment still points at it
+func sortSections(lines []formatLine) {
+	for start := 0; start < len(lines); {
+		end := start
+		var slots []int
+		for ; end < len(lines) && (lines[end].assignment || lines[end].blank); end++ {
+			pinned := end > 0 && strings.Contains(lines[end-1].text, "envdoc:disable-next-line")
+			if lines[end].assignment && !pinned {
+				slots = append(slots, end)
+			}
+		}
+
+		section := make([]formatLine, len(slots))
+		for i, slot := range slots {
+			section[i] = lines[slot]
+		}
+		// stable, so duplicate keys keep their order and the first one still wins
+		sort.SliceStable(section, func(i, j int) bool { return section[i].key < section[j].key })
+		for i, slot := range slots {
+			lines[slot] = section[i]
+		}
+
+		start = end + 1
+	}
+}
+
+// verifyFormat parses both versions and fails if they don't hold the same variables
+func verifyFormat(before, after string, config types.Config) error {
+	want, _, _, err := Parse(strings.NewReader(before), config)
+	if err != nil {
+		return err
+	}
+	got, _, _, err := Parse(strings.NewReader(after), config)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(want))
+	for key := range want {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		envVar, ok := got[key]
+		if !ok {
+			return fmt.Errorf("formatting would drop %s, file left untouched", key)
+		}
+		if envVar.Value != want[key].Value {
+			return fmt.Errorf("formatting would change the value of %s, file left untouched", key)
+		}
+	}
+	for key := range got {
+		if _, ok := want[key]; !ok {
+			return fmt.Errorf("formatting would add %s, file left untouched", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Stdin is the file name that reads standard input instead, as in `kubectl ... | envdoc -`
+const Stdin = "-"
+
+// stdin is read once and kept, commands that look at a file twice get the same content both times
+var stdin *string
+
+// ReadInput returns the contents of a .env file, or of standard input when filename is "-"
+func ReadInput(filename string) (string, error) {
+	if filename != Stdin {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return "", fmt.Errorf("error opening file %s: %v", filename, err)
+		}
+		return string(content), nil
+	}
+
+	if stdin == nil {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("error reading standard input: %v", err)
+		}
+		s := string(content)
+		stdin = &s
+	}
+	return *stdin, nil
+}
+
+// DisplayName is how a file named on the command line shows up in reports
+func DisplayName(filename string) string {
+	if filename == Stdin {
+		return "<stdin>"
+	}
+	return filename
+}
@@ -1,170 +1,243 @@
 package parser
 
 import (
-	"bufio"
 	"fmt"
-	"log"
-	"os"
+	"io"
 	"regexp"
+	"slices"
 	"strings"
+	"unicode"
 
+	"github.com/adnaneAkk/envdoc/internal/lint"
 	"github.com/adnaneAkk/envdoc/internal/types"
 )
 
-// ParseFile parses an .env file and returns the parsed map, errors, and warnings
+// ParseFile parses an .env file, or standard input for "-", and returns the parsed map, errors, and warnings
 func ParseFile(filename string, config types.Config) (types.EnvVarMap, []types.Issue, []types.Issue, error) {
+	content, err := ReadInput(filename)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return Parse(strings.NewReader(content), config)
+}
+
+// Parse is ParseFile for .env content from anywhere, a pipe, an editor buffer or a string
+func Parse(r io.Reader, config types.Config) (types.EnvVarMap, []types.Issue, []types.Issue, error) {
 	var errors []types.Issue
 	var warnings []types.Issue
 
-	file, err := os.Open(filename)
-	if err != nil {
-		// log.Fatalf("Error opening file %s: %v", filename, err)
-		return nil, nil, nil, fmt.Errorf("error opening file %s: %v", filename, err)
+	if _, err := LookupDialect(config.Dialect); err != nil {
+		return nil, nil, nil, err
 	}
-	defer file.Close()
 
 	envVarMap := types.EnvVarMap{}
 
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
+	// read everything up front, a quoted value can run over several lines
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading file: %v", err)
+	}
+	var lines []string
+	var starts []int // byte offset of each line, for turning columns into offsets
+	for src, offset := string(data), 0; src != ""; {
+		line, newline, rest := cutLine(src)
+		lines = append(lines, line)
+		starts = append(starts, offset)
+		offset += len(line) + len(newline)
+		src = rest
+	}
+	locate := func(lineNum int, span types.Span) types.Span {
+		if span.Column == 0 || lineNum < 1 || lineNum > len(starts) {
+			return span
+		}
+		span.Offset = starts[lineNum-1] + span.Column - 1
+		span.EndOffset = starts[lineNum-1] + span.EndColumn - 1
+		return span
+	}
 
-		key, value := parseLine(line, lineNum, config, &errors, &warnings)
+	sups := lint.ParseSuppressions(lines)
+	previous := map[string]lint.Entry{} // latest occurrence of each key
 
-		if key == "" {
+	for i := 0; i < len(lines); i++ {
+		entry, ok := parseLine(lines[i], i+1, lines[i+1:], config)
+		if !ok {
 			continue
 		}
+		i = entry.EndLine - 1
+
+		entry.Lines = lines[entry.LineNum-1 : entry.EndLine]
 
 		// Check for duplicates
-		if _, exists := envVarMap[key]; exists {
-			issue := types.Issue{
-				LineNum:   lineNum,
-				IssueType: "duplicate",
-				Message:   fmt.Sprintf("Duplicate key detected; first occurrence on line %d", envVarMap[key].LineNum),
-				KeyName:   key,
-			}
-			if config.Strict {
-				errors = append(errors, issue)
-			} else {
-				warnings = append(warnings, issue)
+		first, exists := envVarMap[entry.Key]
+		if exists {
+			entry.FirstLine = first.LineNum
+			prev := previous[entry.Key]
+			entry.PrevLine, entry.PrevEndLine = prev.LineNum, prev.EndLine
+		}
+		if entry.Key != "" {
+			previous[entry.Key] = entry
+		}
+
+		lint.Check(entry, config.Strict, sups, &errors, &warnings)
+
+		if entry.Key != "" && !exists {
+			envVarMap[entry.Key] = types.EnvVar{
+				Value:     entry.Value,
+				Quote:     entry.Quote,
+				LineNum:   entry.LineNum,
+				EndLine:   entry.EndLine,
+				Ignore:    sups.Rules(entry.LineNum, entry.EndLine),
+				KeySpan:   locate(entry.LineNum, entry.KeySpan),
+				ValueSpan: locate(entry.LineNum, entry.ValueSpan),
 			}
-		} else {
-			envVarMap[key] = types.EnvVar{Value: value, LineNum: lineNum}
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		log.Fatalf("Error reading file: %v", err)
+	if config.Expand {
+		expandErrors, expandWarnings := Expand(envVarMap, config)
+		errors = append(errors, sups.Filter(expandErrors, envVarMap)...)
+		warnings = append(warnings, sups.Filter(expandWarnings, envVarMap)...)
+	}
+	// schema rules run later under validate, so comments about them can't be judged here
+	checked := func(rule string) bool {
+		return strings.HasPrefix(rule, "ED0") && (config.Expand || !slices.Contains(expandRules, rule))
+	}
+	sups.Report(config.Strict, checked, &errors, &warnings)
+
+	for _, issues := range [][]types.Issue{errors, warnings} {
+		for i := range issues {
+			issues[i].Span = locate(issues[i].LineNum, issues[i].Span)
+		}
 	}
 
 	return envVarMap, errors, warnings, nil
 }
 
-func parseLine(line string, lineNum int, cfg types.Config, errors, warnings *[]types.Issue) (string, string) {
+// parseLine reads one line into an entry for the rules to check, rest holds the lines after it in case
+// the value continues onto them and EndLine says how far it ran. ok is false for blank lines and comments
+func parseLine(line string, lineNum int, rest []string, cfg types.Config) (lint.Entry, bool) {
+	// positions are columns of the line as written, so remember how much trimming moved it
+	indent := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
 	line = strings.TrimSpace(line)
+	entry := lint.Entry{LineNum: lineNum, EndLine: lineNum, Text: lint.Columns(indent, len(line))}
 
 	// Skip empty lines and comments
 	if line == "" || line[0] == '#' {
-		return "", ""
+		return entry, false
 	}
 
 	// Check for equals sign
 	if !strings.Contains(line, "=") {
-		*errors = append(*errors, types.Issue{
-			LineNum:   lineNum,
-			IssueType: "syntax",
-			Message:   "missing '='",
-			KeyName:   "",
-		})
-		return "", ""
+		return entry, true
 	}
 
 	parts := strings.SplitN(line, "=", 2)
-	return checkAfterSplit(parts, lineNum, cfg, errors, warnings)
+	entry.Equals = lint.Columns(indent+len(parts[0]), 1)
+	checkAfterSplit(&entry, parts, rest, cfg)
+	return entry, true
 }
 
-func checkAfterSplit(parts []string, lineNum int, cfg types.Config, errors, warnings *[]types.Issue) (string, string) {
+func checkAfterSplit(entry *lint.Entry, parts []string, rest []string, cfg types.Config) {
+	dialect := dialectOf(cfg.Dialect)
 	key := strings.TrimSpace(parts[0])
 	value := strings.TrimSpace(parts[1])
 
-	// Validate key
+	if dialect.Export() {
+		if after, ok := strings.CutPrefix(key, "export "); ok {
+			key = strings.TrimSpace(after)
+		}
+	}
+
+	// spans are counted from the '=', which parseLine has placed on the original line
+	eq := entry.Equals.Column - 1
+	valueStart := eq + 1 + len(parts[1]) - len(strings.TrimLeftFunc(parts[1], unicode.IsSpace))
+	entry.ValueSpan = lint.Columns(valueStart, len(value))
+
+	entry.HasEquals = true
+	entry.Dialect = dialect.Name()
+	entry.Raw = value
+
+	// a missing key is reported and nothing else about the line matters
 	if key == "" {
-		*errors = append(*errors, types.Issue{
-			LineNum:   lineNum,
-			IssueType: "syntax",
-			Message:   "missing key",
-			KeyName:   "",
-		})
-		return "", ""
-	}
-
-	// Warn if value is empty
-	if value == "" {
-		*warnings = append(*warnings, types.Issue{
-			LineNum:   lineNum,
-			IssueType: "warning",
-			Message:   "missing value",
-			KeyName:   key,
-		})
-	}
-
-	// Handle quoted or unquoted values
-	if isQuoted(value) {
-		handleQuotedParsing(key, &value, lineNum, cfg, errors, warnings)
+		return
+	}
+	entry.Key = key
+	entry.KeySpan = lint.Columns(eq-len(parts[0])+strings.LastIndex(parts[0], key), len(key))
+
+	// Handle heredoc, quoted or unquoted values
+	consumed := 0
+	if marker, ok := heredocMarker(value); ok && dialect.Heredoc() {
+		consumed = handleHeredocParsing(entry, &value, marker, rest)
+	} else if isQuoted(value) {
+		entry.Quote = value[0]
+		consumed = handleQuotedParsing(entry, &value, rest, cfg)
 	} else {
-		handleUnQuotedParsing(key, &value, lineNum, cfg, errors, warnings)
-	}
-
-	// Strict mode key validation
-	if cfg.Strict {
-		if valid, _ := strictKeyRegex(key); !valid {
-			*errors = append(*errors, types.Issue{
-				LineNum:   lineNum,
-				IssueType: "strict",
-				Message:   "invalid strict key (must be uppercase with underscores)",
-				KeyName:   key,
-			})
-			return "", ""
-		}
+		handleUnQuotedParsing(entry, &value, cfg)
 	}
 
-	return key, value
+	entry.Value = value
+	entry.EndLine = entry.LineNum + consumed
 }
 
-func handleUnQuotedParsing(key string, v *string, lineNum int, cfg types.Config, errors, warnings *[]types.Issue) {
+func handleUnQuotedParsing(entry *lint.Entry, v *string, cfg types.Config) {
 	value := *v
 
+	dialect := dialectOf(cfg.Dialect)
+
 	// Strip inline comments
-	if idx := strings.Index(value, "#"); idx != -1 {
+	if idx := dialect.InlineComment(value); idx != -1 {
 		value = strings.TrimSpace(value[:idx])
 	}
+	entry.ValueSpan.EndColumn = entry.ValueSpan.Column + len(value)
 
-	// Check for dangling backslash
-	if len(value) > 0 && value[len(value)-1] == '\\' {
-		issue := types.Issue{
-			LineNum:   lineNum,
-			IssueType: "warning",
-			Message:   "dangling escape at end of value",
-			KeyName:   key,
-		}
-		if cfg.Strict {
-			issue.IssueType = "strict"
-			*errors = append(*errors, issue)
-		} else {
-			*warnings = append(*warnings, issue)
+	// sh ends the assignment at the first unescaped space, what follows would be run as a command
+	if !dialect.UnquotedSpaces() {
+		if idx := unquotedSpace(value); idx != -1 {
+			value = value[:idx]
+			entry.SpaceCut = true
 		}
 	}
 
+	// Check for dangling backslash, an even run of them is escaped backslashes
+	trailing := len(value) - len(strings.TrimRight(value, `\`))
+	if trailing%2 == 1 {
+		entry.DanglingEscape = true
+	}
+
 	*v = value
 }
 
-func handleQuotedParsing(key string, v *string, lineNum int, cfg types.Config, errors, warnings *[]types.Issue) {
+// unquotedSpace is the index of the first whitespace not escaped by a backslash, -1 if there's none
+func unquotedSpace(value string) int {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ' ', '\t':
+			return i
+		}
+	}
+	return -1
+}
+
+// handleQuotedParsing unquotes *v in place. A value that doesn't close on its own line is joined
+// with the following lines up to the closing quote, the number of extra lines used is returned
+func handleQuotedParsing(entry *lint.Entry, v *string, rest []string, cfg types.Config) int {
 	value := *v
 	firstChar := value[0]
 
+	// PEM blocks and the like: only join lines when the quote does close further down,
+	// otherwise one typo would swallow the rest of the file
+	dialect := dialectOf(cfg.Dialect)
+	consumed := 0
+	if !dialect.Multiline(firstChar) {
+		rest = nil
+	}
+	if joined, end, extra := quotedEnd(value, rest); end != -1 {
+		value, consumed = joined, extra
+	}
+	entry.Swallowed = consumed > 0 && swallowsAssignments(rest[:consumed])
+
 	for i := 1; i < len(value); i++ {
 		if value[i] == firstChar {
 			// Count preceding backslashes
@@ -178,77 +251,102 @@ func handleQuotedParsing(key string, v *string, lineNum int, cfg types.Config, e
 			// Even number = not escaped
 			if backslashes%2 == 0 {
 				// Check for content after closing quote
-				ignoredString := value[i+1:]
-				if len(ignoredString) > 0 {
-					ignoredString = strings.TrimSpace(ignoredString)
-					if len(ignoredString) > 0 && ignoredString[0] != '#' {
-						issue := types.Issue{
-							LineNum:   lineNum,
-							IssueType: "warning",
-							Message:   "content after closing quote",
-							KeyName:   key,
-						}
-						if cfg.Strict {
-							issue.IssueType = "strict"
-							*errors = append(*errors, issue)
-						} else {
-							*warnings = append(*warnings, issue)
-						}
-					}
+				ignoredString := strings.TrimSpace(value[i+1:])
+				if len(ignoredString) > 0 && ignoredString[0] != '#' {
+					entry.Trailing = ignoredString
+					entry.TrailingLine = entry.LineNum + consumed
+				}
+				if consumed == 0 {
+					entry.ValueSpan.EndColumn = entry.ValueSpan.Column + i + 1
 				}
 
-				value = value[1:i]
+				value = dialect.Unescape(value[1:i], firstChar)
 				*v = value
-				return
+				return consumed
 			}
 		} else if value[i] == '\\' {
 			// Skip escaped character
 			if i+1 < len(value) {
 				i++
 			} else {
-				issue := types.Issue{
-					LineNum:   lineNum,
-					IssueType: "warning",
-					Message:   "dangling escape at end of value",
-					KeyName:   key,
-				}
-				if cfg.Strict {
-					issue.IssueType = "strict"
-					*errors = append(*errors, issue)
-				} else {
-					*warnings = append(*warnings, issue)
-				}
-				return
+				entry.DanglingEscape = true
+				return 0
 			}
 		}
 
-		// Unclosed quote
 		if i == len(value)-1 {
-			issue := types.Issue{
-				LineNum:   lineNum,
-				IssueType: "warning",
-				Message:   "unclosed quoted value",
-				KeyName:   key,
-			}
-			if cfg.Strict {
-				issue.IssueType = "strict"
-				*errors = append(*errors, issue)
-			} else {
-				*warnings = append(*warnings, issue)
+			break
+		}
+	}
+
+	// Unclosed quote
+	entry.Unclosed = true
+	return 0
+}
+
+// quotedEnd looks for the quote closing value[0], carrying on through the lines in rest.
+// It returns the value joined up to that line, the index of the closing quote (-1 if it never closes)
+// and how many lines of rest were joined
+func quotedEnd(value string, rest []string) (string, int, int) {
+	quote := value[0]
+	i := 1
+	for consumed := 0; ; consumed++ {
+		for ; i < len(value); i++ {
+			switch value[i] {
+			case '\\':
+				i++
+			case quote:
+				return value, i, consumed
 			}
-			return
 		}
+		if consumed == len(rest) {
+			return value, -1, 0
+		}
+		value += "\n" + rest[consumed]
 	}
 }
 
+var assignmentRegex = regexp.MustCompile(`^\s*(export\s+)?[A-Za-z_][A-Za-z0-9_]*\s*=`)
+
+func swallowsAssignments(lines []string) bool {
+	for _, line := range lines {
+		if assignmentRegex.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+var heredocRegex = regexp.MustCompile(`^<<-?(['"]?)([A-Za-z_][A-Za-z0-9_]*)(['"]?)$`)
+
+// heredocMarker reports whether value opens a heredoc like <<EOF and returns the terminator
+func heredocMarker(value string) (string, bool) {
+	match := heredocRegex.FindStringSubmatch(value)
+	if match == nil || match[1] != match[3] {
+		return "", false
+	}
+	return match[2], true
+}
+
+// handleHeredocParsing collects the lines up to the terminator into *v and returns how many lines it used
+func handleHeredocParsing(entry *lint.Entry, v *string, marker string, rest []string) int {
+	entry.Heredoc = marker
+	for n, line := range rest {
+		if strings.TrimSpace(line) == marker {
+			*v = strings.Join(rest[:n], "\n")
+			return n + 1
+		}
+	}
+
+	entry.HeredocOpen = true
+	return 0
+}
+
 func isQuoted(value string) bool {
-	if len(value) >= 2 {
+	// a lone quote still counts, it opens a value spanning the next lines
+	if len(value) >= 1 {
 		firstChar := value[0]
 		return firstChar == '"' || firstChar == '\''
 	}
 	return false
 }
-
-func strictKeyRegex(key string) (bool, error) {
-	return regexp.MatchString("^[A-Z_][A-Z0-9_]*$", key)
-}
@@ -0,0 +1,337 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/lint"
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// JSON writes one object per file with its errors and warnings
+type JSON struct{}
+
+type jsonIssue struct {
+	Line      int      `json:"line"`
+	Column    int      `json:"column,omitempty"`
+	EndColumn int      `json:"endColumn,omitempty"`
+	Offset    *int     `json:"offset,omitempty"`
+	EndOffset *int     `json:"endOffset,omitempty"`
+	Rule      string   `json:"rule"`
+	Type      string   `json:"type"`
+	Severity  string   `json:"severity"`
+	Message   string   `json:"message"`
+	Key       string   `json:"key,omitempty"`
+	Fix       *jsonFix `json:"fix,omitempty"`
+}
+
+type jsonFix struct {
+	Description string `json:"description"`
+	Line        int    `json:"line"`
+	EndLine     int    `json:"endLine"`
+	Text        string `json:"text"`
+	Delete      bool   `json:"delete,omitempty"`
+}
+
+type jsonFile struct {
+	File     string      `json:"file"`
+	Errors   []jsonIssue `json:"errors"`
+	Warnings []jsonIssue `json:"warnings"`
+}
+
+func (JSON) Report(w io.Writer, reports []types.Report) error {
+	convert := func(issues []types.Issue, severity string) []jsonIssue {
+		out := make([]jsonIssue, 0, len(issues))
+		for _, i := range issues {
+			issue := jsonIssue{Line: i.LineNum, Rule: i.Rule, Type: i.IssueType, Severity: severity, Message: i.Message, Key: i.KeyName}
+			// offset 0 is a real position, the first byte of the file, so only a missing column leaves them out
+			if i.Column > 0 {
+				issue.Column, issue.EndColumn = i.Column, i.EndColumn
+				issue.Offset, issue.EndOffset = &i.Offset, &i.EndOffset
+			}
+			if i.Fix != nil {
+				issue.Fix = &jsonFix{Description: i.Fix.Description, Line: i.Fix.Line, EndLine: i.Fix.EndLine, Text: i.Fix.Text, Delete: i.Fix.Delete}
+			}
+			out = append(out, issue)
+		}
+		return out
+	}
+
+	files := make([]jsonFile, 0, len(reports))
+	for _, r := range reports {
+		files = append(files, jsonFile{
+			File:     r.File,
+			Errors:   convert(r.Errors, "error"),
+			Warnings: convert(r.Warnings, "warning"),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string][]jsonFile{"files": files})
+}
+
+// SARIF writes a SARIF 2.1.0 log, which GitHub code scanning and most CI dashboards read
+type SARIF struct{}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name,omitempty"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int  `json:"startLine"`
+	StartColumn int  `json:"startColumn,omitempty"`
+	EndColumn   int  `json:"endColumn,omitempty"`
+	ByteOffset  *int `json:"byteOffset,omitempty"`
+	ByteLength  *int `json:"byteLength,omitempty"`
+}
+
+func (SARIF) Report(w io.Writer, reports []types.Report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "envdoc",
+			InformationURI: "https://github.com/adnaneAkk/envdoc",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	seen := map[string]bool{}
+	for _, f := range findings(reports) {
+		if !seen[f.Rule] {
+			seen[f.Rule] = true
+			rule := sarifRule{ID: f.Rule, Name: f.IssueType, ShortDescription: sarifMessage{Text: f.IssueType}}
+			if info, ok := lint.Lookup(f.Rule); ok {
+				rule.ShortDescription.Text = info.Summary
+				rule.HelpURI = info.URL()
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: f.File}}
+		// line 0 means the issue is about the file as a whole (a missing key), SARIF lines start at 1
+		if f.LineNum > 0 {
+			location.Region = &sarifRegion{StartLine: f.LineNum}
+			if f.Column > 0 {
+				length := f.EndOffset - f.Offset
+				location.Region.StartColumn, location.Region.EndColumn = f.Column, f.EndColumn
+				location.Region.ByteOffset, location.Region.ByteLength = &f.Offset, &length
+			}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    f.Rule,
+			Level:     f.Severity,
+			Message:   sarifMessage{Text: f.title()},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// JUnit writes one test suite per file and one test case per issue, errors fail and warnings pass
+type JUnit struct{}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (JUnit) Report(w io.Writer, reports []types.Report) error {
+	suites := junitSuites{}
+
+	for _, r := range reports {
+		suite := junitSuite{Name: r.File}
+		for _, f := range findings([]types.Report{r}) {
+			c := junitCase{
+				Name:      fmt.Sprintf("line %d: %s", f.LineNum, f.title()),
+				ClassName: f.File,
+			}
+			if f.Severity == "error" {
+				c.Failure = &junitFailure{Message: f.Message, Type: f.Rule, Text: f.title()}
+				suite.Failures++
+			} else {
+				c.SystemOut = fmt.Sprintf("warning [%s]: %s", Label(f.Issue), f.title())
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		// a clean file still shows up as one passing test
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitCase{Name: "valid", ClassName: r.File})
+		}
+		suite.Tests = len(suite.Cases)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// GitHub writes workflow commands so GitHub Actions annotates the offending lines
+type GitHub struct{}
+
+func (GitHub) Report(w io.Writer, reports []types.Report) error {
+	for _, f := range findings(reports) {
+		properties := "file=" + escapeGitHubProperty(f.File)
+		if f.LineNum > 0 {
+			properties += fmt.Sprintf(",line=%d", f.LineNum)
+		}
+		if f.Column > 0 {
+			properties += fmt.Sprintf(",col=%d,endColumn=%d", f.Column, f.EndColumn)
+		}
+		properties += ",title=" + escapeGitHubProperty("envdoc "+Label(f.Issue))
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", f.Severity, properties, escapeGitHubData(f.title())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// Checkstyle writes the checkstyle XML format understood by Jenkins, reviewdog and friends
+type Checkstyle struct{}
+
+type checkstyleResult struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+func (Checkstyle) Report(w io.Writer, reports []types.Report) error {
+	result := checkstyleResult{Version: "4.3"}
+	for _, r := range reports {
+		file := checkstyleFile{Name: r.File}
+		for _, f := range findings([]types.Report{r}) {
+			file.Errors = append(file.Errors, checkstyleError{
+				Line:     f.LineNum,
+				Column:   f.Column,
+				Severity: f.Severity,
+				Message:  f.title(),
+				Source:   "envdoc." + f.Rule,
+			})
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// Reporter writes the issues found in one or more files in a single output format
+type Reporter interface {
+	Report(w io.Writer, reports []types.Report) error
+}
+
+var reporters = map[string]Reporter{
+	"text":       Text{},
+	"json":       JSON{},
+	"sarif":      SARIF{},
+	"junit":      JUnit{},
+	"github":     GitHub{},
+	"checkstyle": Checkstyle{},
+}
+
+// New returns the reporter for format
+func New(format string) (Reporter, error) {
+	reporter, ok := reporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format: %s (use %s)", format, strings.Join(Formats(), ", "))
+	}
+	return reporter, nil
+}
+
+// Formats lists the supported output formats
+func Formats() []string {
+	formats := make([]string, 0, len(reporters))
+	for format := range reporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Text is the human readable output envdoc has always printed
+type Text struct {
+	Headers bool // name the file even when there's only one, for commands that find the files themselves
+}
+
+func (t Text) Report(w io.Writer, reports []types.Report) error {
+	for i, r := range reports {
+		if (len(reports) > 1 || t.Headers) && (len(r.Errors) > 0 || len(r.Warnings) > 0) {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "=== %s ===\n", r.File)
+		}
+		lines := sourceLines(r)
+
+		// Print errors
+		if len(r.Errors) > 0 {
+			fmt.Fprintf(w, "Errors: %d found\n", len(r.Errors))
+			for _, e := range r.Errors {
+				fmt.Fprintf(w, "  Line %d [%s]: %s (Key: %s)\n", e.LineNum, Label(e), e.Message, e.KeyName)
+				writeSnippet(w, lines, e)
+			}
+		}
+
+		// Print warnings
+		if len(r.Warnings) > 0 {
+			fmt.Fprintf(w, "\nWarnings: %d found\n", len(r.Warnings))
+			for _, w2 := range r.Warnings {
+				fmt.Fprintf(w, "  Line %d [%s]: %s (Key: %s)\n", w2.LineNum, Label(w2), w2.Message, w2.KeyName)
+				writeSnippet(w, lines, w2)
+			}
+		}
+	}
+	return nil
+}
+
+// sourceLines is the report's file split into lines, nil when it can't be read
+func sourceLines(r types.Report) []string {
+	source := r.Source
+	if source == "" && r.File != "" {
+		data, err := os.ReadFile(r.File)
+		if err != nil {
+			return nil
+		}
+		source = string(data)
+	}
+	if source == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+}
+
+// writeSnippet prints the line an issue is on with carets under the part it's about:
+//
+//	4 | NAME="envdoc" extra
+//	  |               ^^^^^
+func writeSnippet(w io.Writer, lines []string, issue types.Issue) {
+	if issue.Column == 0 || issue.LineNum < 1 || issue.LineNum > len(lines) {
+		return
+	}
+	line := lines[issue.LineNum-1]
+	start := min(issue.Column-1, len(line))
+	end := min(max(issue.EndColumn-1, start+1), max(len(line), start+1))
+
+	// keep tabs so the carets line up however the terminal draws them
+	var pad strings.Builder
+	for _, c := range []byte(line[:start]) {
+		if c == '\t' {
+			pad.WriteByte('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+	gutter := fmt.Sprintf("%6d", issue.LineNum)
+	fmt.Fprintf(w, "%s | %s\n", gutter, line)
+	fmt.Fprintf(w, "%s | %s%s\n", strings.Repeat(" ", len(gutter)), pad.String(), strings.Repeat("^", end-start))
+}
+
+// Label names the rule behind an issue the way text output shows it, e.g. "ED006 duplicate-key"
+func Label(issue types.Issue) string {
+	if issue.Rule == "" {
+		return issue.IssueType
+	}
+	return issue.Rule + " " + issue.IssueType
+}
+
+// finding is an issue flattened with its file and severity, the shape most formats want
+type finding struct {
+	File     string
+	Severity string // "error" or "warning"
+	types.Issue
+}
+
+func findings(reports []types.Report) []finding {
+	var all []finding
+	for _, r := range reports {
+		for _, e := range r.Errors {
+			all = append(all, finding{File: r.File, Severity: "error", Issue: e})
+		}
+		for _, w := range r.Warnings {
+			all = append(all, finding{File: r.File, Severity: "warning", Issue: w})
+		}
+	}
+	return all
+}
+
+// title is the one-line summary used by formats that show a heading per issue
+func (f finding) title() string {
+	if f.KeyName != "" {
+		return fmt.Sprintf("%s (Key: %s)", f.Message, f.KeyName)
+	}
+	return f.Message
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenReports has an issue with a span and a fix, a file-level issue on line 0, a warning
+// with a delete fix and a clean second file
+var goldenReports = []types.Report{
+	{
+		File:   ".env",
+		Source: "DB_HOST=localhost\nNAME=\"envdoc\" extra\nPORT=3000\nPORT=8080\n",
+		Errors: []types.Issue{
+			{
+				LineNum:   2,
+				IssueType: "content-after-quote",
+				Rule:      "ED009",
+				Message:   "content after closing quote: extra",
+				KeyName:   "NAME",
+				Span:      types.Span{Column: 15, EndColumn: 20, Offset: 32, EndOffset: 37},
+				Fix: &types.Fix{
+					Description: "turn the text into a comment",
+					Line:        2,
+					EndLine:     2,
+					Text:        `NAME="envdoc" # extra`,
+				},
+			},
+			{
+				LineNum:   0,
+				IssueType: "required-key",
+				Rule:      "ED101",
+				Message:   "required key is missing",
+				KeyName:   "API_URL",
+			},
+		},
+		Warnings: []types.Issue{
+			{
+				LineNum:   4,
+				IssueType: "duplicate-key",
+				Rule:      "ED006",
+				Message:   "duplicate key, first defined on line 3",
+				KeyName:   "PORT",
+				Span:      types.Span{Column: 1, EndColumn: 5, Offset: 48, EndOffset: 52},
+				Fix: &types.Fix{
+					Description: "remove this assignment",
+					Line:        4,
+					EndLine:     4,
+					Delete:      true,
+				},
+			},
+		},
+	},
+	{
+		File:   "config/.env.production",
+		Source: "APP_ENV=production\n",
+	},
+}
+
+// every reporter has a golden file, so adding a format without one fails here
+func TestReportersGolden(t *testing.T) {
+	for _, format := range Formats() {
+		t.Run(format, func(t *testing.T) {
+			reporter, err := New(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := reporter.Report(&buf, goldenReports); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", format+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("%s output changed\n got:\n%s\nwant:\n%s", format, got, want)
+			}
+		})
+	}
+}
+
+// a lone file is only named when asked, commands that find their own files always ask
+func TestTextHeaders(t *testing.T) {
+	single := goldenReports[:1]
+	for _, headers := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := (Text{Headers: headers}).Report(&buf, single); err != nil {
+			t.Fatal(err)
+		}
+		if named := bytes.HasPrefix(buf.Bytes(), []byte("=== .env ===\n")); named != headers {
+			t.Errorf("Headers %v: output starts %q", headers, buf.String()[:20])
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package scan
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// pattern is one line of a .gitignore, or one --include/--exclude glob, which use the same syntax
+type pattern struct {
+	re      *regexp.Regexp
+	negate  bool // !pattern, re-includes what an earlier pattern ignored
+	dirOnly bool // pattern/, only matches directories
+}
+
+// compilePattern turns a gitignore pattern into a regexp over slash-separated paths relative to
+// the directory the pattern belongs to. ok is false for blank lines and comments
+func compilePattern(line string) (pattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || line[0] == '#' {
+		return pattern{}, false
+	}
+
+	var p pattern
+	if line[0] == '!' {
+		p.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`) // \# and \! are literal
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// a slash anywhere but the end ties the pattern to its directory, otherwise it matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return pattern{}, false
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case strings.HasPrefix(line[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(line[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(line[i+1:], ']')
+			if end == -1 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := line[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return pattern{}, false
+	}
+	p.re = re
+	return p, true
+}
+
+// patternList is a set of patterns that apply below base, later patterns win like in git
+type patternList struct {
+	base     string // directory the patterns are relative to, "" for the scan root
+	patterns []pattern
+}
+
+func newPatternList(base string, lines []string) *patternList {
+	list := &patternList{base: base}
+	for _, line := range lines {
+		if p, ok := compilePattern(line); ok {
+			list.patterns = append(list.patterns, p)
+		}
+	}
+	return list
+}
+
+// readGitignore loads dir/.gitignore, nil when there isn't one
+func readGitignore(dir, base string) *patternList {
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return newPatternList(base, lines)
+}
+
+// match reports whether any pattern matches rel (relative to the scan root), and if so whether
+// the last one to match includes or excludes it
+func (l *patternList) match(rel string, isDir bool) (matched, negated bool) {
+	if l.base != "" {
+		var ok bool
+		if rel, ok = strings.CutPrefix(rel, l.base+"/"); !ok {
+			return false, false
+		}
+	}
+	for _, p := range l.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(rel) {
+			matched, negated = true, p.negate
+		}
+	}
+	return matched, negated
+}
+
+// matches reports whether the list as a whole selects rel
+func (l *patternList) matches(rel string, isDir bool) bool {
+	matched, negated := l.match(rel, isDir)
+	return matched && !negated
+}
+
+// ignored runs rel through a stack of pattern lists, deeper lists have the last word
+func ignored(lists []*patternList, rel string, isDir bool) bool {
+	result := false
+	for _, l := range lists {
+		if matched, negated := l.match(rel, isDir); matched {
+			result = !negated
+		}
+	}
+	return result
+}
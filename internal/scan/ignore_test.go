@@ -0,0 +1,121 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool // selected by the list as a whole
+	}{
+		// no slash matches at any depth, a leading or middle slash ties it to the root
+		{"unanchored at root", []string{".env"}, ".env", false, true},
+		{"unanchored nested", []string{".env"}, "a/b/.env", false, true},
+		{"leading slash at root", []string{"/.env"}, ".env", false, true},
+		{"leading slash nested", []string{"/.env"}, "a/.env", false, false},
+		{"middle slash", []string{"config/.env"}, "config/.env", false, true},
+		{"middle slash nested", []string{"config/.env"}, "app/config/.env", false, false},
+		{"star stays in its segment", []string{"*.env"}, "prod.env", false, true},
+		{"star doesn't cross a slash", []string{"config/*.env"}, "config/a/prod.env", false, false},
+		{"question mark", []string{".env.?"}, ".env.1", false, true},
+		{"class", []string{".env.[ab]"}, ".env.b", false, true},
+		{"negated class", []string{".env.[!ab]"}, ".env.b", false, false},
+
+		// ** spans any number of directories, none included
+		{"leading ** at root", []string{"**/.env"}, ".env", false, true},
+		{"leading ** nested", []string{"**/.env"}, "a/b/.env", false, true},
+		{"middle ** no directories", []string{"a/**/.env"}, "a/.env", false, true},
+		{"middle ** several directories", []string{"a/**/.env"}, "a/x/y/.env", false, true},
+		{"middle ** other root", []string{"a/**/.env"}, "b/x/.env", false, false},
+		{"trailing ** below", []string{"vendor/**"}, "vendor/x/.env", false, true},
+		{"trailing ** not the directory itself", []string{"vendor/**"}, "vendor", true, false},
+
+		// the last pattern to match decides
+		{"negation re-includes", []string{"*.env", "!keep.env"}, "keep.env", false, false},
+		{"negation leaves the rest", []string{"*.env", "!keep.env"}, "drop.env", false, true},
+		{"ignored again after negation", []string{"*.env", "!keep.env", "keep.env"}, "keep.env", false, true},
+		{"escaped bang is literal", []string{`\!important.env`}, "!important.env", false, true},
+		{"escaped hash is literal", []string{`\#notes.env`}, "#notes.env", false, true},
+		{"comment is no pattern", []string{"# .env"}, "# .env", false, false},
+
+		// a trailing slash only matches directories
+		{"directory-only on a directory", []string{"logs/"}, "logs", true, true},
+		{"directory-only on a file", []string{"logs/"}, "logs", false, false},
+		{"directory-only nested", []string{"logs/"}, "app/logs", true, true},
+		{"anchored directory-only", []string{"/logs/"}, "app/logs", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := newPatternList("", tt.patterns)
+			if got := list.matches(tt.path, tt.isDir); got != tt.want {
+				var res []string
+				for _, p := range list.patterns {
+					res = append(res, p.re.String())
+				}
+				t.Errorf("%q on %s = %v, want %v (compiled to %s)", tt.patterns, tt.path, got, tt.want, strings.Join(res, " "))
+			}
+		})
+	}
+}
+
+// .gitignore files apply below their own directory and deeper ones have the last word, but like
+// git nothing can be re-included from a directory that is ignored as a whole
+func TestFindGitignore(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":          "*.local\nsecrets/\n!secrets/.env\nbuild/*\n!build/keep.env\n/top.env\n",
+		".env":                "",
+		".env.local":          "",
+		"top.env":             "",
+		"secrets/.env":        "",
+		"build/.env":          "",
+		"build/keep.env":      "",
+		"app/.env":            "",
+		"app/.env.local":      "",
+		"app/top.env":         "",
+		"app/.gitignore":      "!.env.local\n.env.test\n",
+		"app/.env.test":       "",
+		"app/deep/.env.local": "",
+		"app/deep/.env.test":  "",
+		"other/.env.test":     "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := Find(root, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, file := range found {
+		got = append(got, file.Rel)
+	}
+
+	want := []string{
+		".env",
+		"app/.env",
+		"app/.env.local",      // re-included by app/.gitignore
+		"app/deep/.env.local", // and below it
+		"app/top.env",         // /top.env only ignores the one at the root
+		"build/keep.env",      // build/* ignores the contents, not the directory
+		"other/.env.test",     // app/.gitignore doesn't reach here
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Find = %q\nwant   %q", got, want)
+	}
+}
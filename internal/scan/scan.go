@@ -0,0 +1,166 @@
+package scan
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// DefaultInclude matches the usual names: .env, .env.production, .env.example, production.env, ...
+var DefaultInclude = []string{".env", ".env.*", "*.env"}
+
+// Options picks the files Find returns. Patterns use .gitignore syntax and are relative to the scan root
+type Options struct {
+	Include []string // a file has to match one of these, DefaultInclude when empty
+	Exclude []string // drops matching files, and whole directories
+}
+
+// File is a .env file found under the scan root
+type File struct {
+	Path string // root joined with Rel, for opening
+	Rel  string // relative to the root with forward slashes, for showing
+	Kind string // what the name says the file is for, see Classify
+}
+
+// Find walks root and returns the .env files in it in lexical order, skipping .git,
+// anything a .gitignore along the way ignores and anything excluded
+func Find(root string, opts Options) ([]File, error) {
+	include := opts.Include
+	if len(include) == 0 {
+		include = DefaultInclude
+	}
+	includes := newPatternList("", include)
+	excludes := newPatternList("", opts.Exclude)
+
+	var files []File
+	var gitignores []*patternList
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel == "." {
+				rel = ""
+			} else if d.Name() == ".git" || excludes.matches(rel, true) || ignored(gitignores, rel, true) {
+				return filepath.SkipDir
+			}
+			if gitignore := readGitignore(path, rel); gitignore != nil {
+				gitignores = append(gitignores, gitignore)
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() || !includes.matches(rel, false) || excludes.matches(rel, false) || ignored(gitignores, rel, false) {
+			return nil
+		}
+		files = append(files, File{Path: path, Rel: rel, Kind: Classify(d.Name())})
+		return nil
+	})
+	return files, err
+}
+
+// examples are the suffixes used for committed templates of a .env file
+var examples = []string{"example", "sample", "template", "dist", "defaults"}
+
+// SchemaNames are the files envdoc schema writes by default
+var SchemaNames = []string{"schema.json", "schema.yaml", "schema.yml"}
+
+// Classify names what a .env file is for from its name: "env" for .env itself, "example" for
+// templates like .env.example, "schema" for a generated schema, otherwise the environment, so
+// .env.production and production.env are both "production" and .env.production.local is "production.local"
+func Classify(name string) string {
+	var env string
+	switch {
+	case slices.Contains(SchemaNames, name):
+		return "schema"
+	case name == ".env":
+		return "env"
+	case strings.HasPrefix(name, ".env."):
+		env = strings.TrimPrefix(name, ".env.")
+	case strings.HasSuffix(name, ".env"):
+		env = strings.TrimSuffix(name, ".env")
+	default:
+		return "other"
+	}
+	for _, example := range examples {
+		if env == example {
+			return "example"
+		}
+	}
+	return env
+}
+
+// Result is what linting one file found
+type Result struct {
+	File   File
+	Report types.Report
+	Keys   int
+	Err    error // the file couldn't be read or parsed at all
+
+	Secrets []Secret // what Secrets found, accepted ones included
+}
+
+// Public reports whether a kind of file is meant to be committed and shared
+func Public(kind string) bool {
+	return kind == "example" || kind == "schema"
+}
+
+// Lint parses every file with up to workers at a time. Results come back in the order of files
+// however the work was scheduled, so output built from them is the same on every run
+func Lint(files []File, config types.Config, workers int) []Result {
+	return each(files, workers, func(file File) Result {
+		return lintFile(file, config)
+	})
+}
+
+// each runs check on every file with up to workers at a time, results in the order of files
+func each(files []File, workers int, check func(File) Result) []Result {
+	results := make([]Result, len(files))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(max(workers, 1), len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// every index goes to exactly one worker, so the writes never overlap
+			for i := range jobs {
+				results[i] = check(files[i])
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func lintFile(file File, config types.Config) Result {
+	result := Result{File: file, Report: types.Report{File: file.Rel}}
+	content, err := parser.ReadInput(file.Path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	envVarMap, errors, warnings, err := parser.Parse(strings.NewReader(content), config)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Report = types.Report{File: file.Rel, Source: content, Errors: errors, Warnings: warnings}
+	result.Keys = len(envVarMap)
+	return result
+}
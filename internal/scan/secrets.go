@@ -0,0 +1,173 @@
+package scan
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/adnaneAkk/envdoc/internal/lint"
+	"github.com/adnaneAkk/envdoc/internal/parser"
+	"github.com/adnaneAkk/envdoc/internal/secrets"
+	"github.com/adnaneAkk/envdoc/internal/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NewFile describes a file named on the command line rather than found by Find
+func NewFile(path string) File {
+	return File{Path: path, Rel: parser.DisplayName(path), Kind: Classify(filepath.Base(path))}
+}
+
+// Secret is a value Secrets took for a real secret. The value stays in memory, it's there to be
+// hashed into a baseline
+type Secret struct {
+	Key   string
+	Line  int
+	Rule  string // the pattern that matched, or entropy
+	Value string
+}
+
+// Accepted tells Secrets which findings were already looked at and let through, nil accepts nothing
+type Accepted func(file File, key, value string) bool
+
+// Secrets looks for real secrets in files that are meant to be shared, reporting each as ED201
+// unless accepted says otherwise. Values that are obviously placeholders and allow-listed keys
+// and values are left alone, and other problems in the files are for envdoc scan to report
+func Secrets(files []File, config types.Config, detector *secrets.Detector, accepted Accepted, workers int) []Result {
+	// a ${VAR} in a template is a reference, not a value to judge
+	config.Expand = false
+	if accepted == nil {
+		accepted = func(File, string, string) bool { return false }
+	}
+	return each(files, workers, func(file File) Result {
+		return secretsFile(file, config, detector, accepted)
+	})
+}
+
+// candidate is a value found in a file, with where it is
+type candidate struct {
+	key    string
+	value  string
+	line   int
+	span   types.Span
+	ignore []string // rules silenced on its lines
+}
+
+func secretsFile(file File, config types.Config, detector *secrets.Detector, accepted Accepted) Result {
+	result := Result{File: file, Report: types.Report{File: file.Rel}}
+	content, err := parser.ReadInput(file.Path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	var values []candidate
+	if file.Kind == "schema" {
+		values, err = schemaValues(content)
+	} else {
+		values, err = envValues(content, config)
+	}
+	if err != nil {
+		result.Err = fmt.Errorf("%s: %v", file.Rel, err)
+		return result
+	}
+
+	var errors, warnings []types.Issue
+	for _, c := range values {
+		if c.value == "" || slices.Contains(c.ignore, "ED201") || detector.AllowedKey(c.key) || secrets.IsPlaceholder(c.value) {
+			continue
+		}
+		findings := detector.ValueFindings(c.value)
+		if len(findings) == 0 {
+			continue
+		}
+		rule := findings[0].Rule
+		if rule == "" {
+			rule = findings[0].Detector
+		}
+		result.Secrets = append(result.Secrets, Secret{Key: c.key, Line: c.line, Rule: rule, Value: c.value})
+		if accepted(file, c.key, c.value) {
+			continue
+		}
+		lint.Add("ED201", types.Issue{
+			LineNum: c.line,
+			KeyName: c.key,
+			Message: fmt.Sprintf("value looks like a real secret (%s), commit a placeholder instead", reason(findings[0])),
+			Span:    c.span,
+		}, config.Strict, &errors, &warnings)
+	}
+
+	result.Report = types.Report{File: file.Rel, Source: content, Errors: errors, Warnings: warnings}
+	result.Keys = len(values)
+	return result
+}
+
+// reason says what gave a value away without quoting it
+func reason(f secrets.Finding) string {
+	if f.Detector == secrets.ValuePattern {
+		return "matches the " + f.Rule + " pattern"
+	}
+	return "high entropy"
+}
+
+func envValues(content string, config types.Config) ([]candidate, error) {
+	envVarMap, _, _, err := parser.Parse(strings.NewReader(content), config)
+	if err != nil {
+		return nil, err
+	}
+	var values []candidate
+	for key, envVar := range envVarMap {
+		values = append(values, candidate{key: key, value: envVar.Value, line: envVar.LineNum, span: envVar.ValueSpan, ignore: envVar.Ignore})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].line < values[j].line })
+	return values, nil
+}
+
+// schemaValues reads the example values out of a schema written by envdoc schema, JSON or YAML.
+// A YAML parser reads both and keeps the positions
+func schemaValues(content string) ([]candidate, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	// the parser only gives lines and columns, the reports want byte offsets as well
+	starts := []int{0}
+	for i, c := range content {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+
+	var values []candidate
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, item := root.Content[i], root.Content[i+1]
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			field, value := item.Content[j], item.Content[j+1]
+			// the JSON schema has no tags so the field keeps its Go name
+			if (field.Value != "example" && field.Value != "Value") || value.Kind != yaml.ScalarNode {
+				continue
+			}
+			column := value.Column
+			if value.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+				column++
+			}
+			span := types.Span{Column: column, EndColumn: column + len(value.Value)}
+			if value.Line <= len(starts) {
+				span.Offset = starts[value.Line-1] + span.Column - 1
+				span.EndOffset = starts[value.Line-1] + span.EndColumn - 1
+			}
+			values = append(values, candidate{key: key.Value, value: value.Value, line: value.Line, span: span})
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,235 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypeDetector describes one value type: how to spot it when generating a schema
+// and how to check a value against it when validating
+type TypeDetector struct {
+	Name string
+	// Detect reports whether the value looks like this type, the key is there for hints like *_PORT
+	Detect func(key, value string) bool
+	// Validate returns nil when the value is acceptable for a key declared with this type
+	Validate func(value string) error
+}
+
+// registry is checked in order when guessing, so the more specific types come first
+var registry []TypeDetector
+
+var booleanValues = map[string]bool{}
+
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func init() {
+	SetBooleanValues([]string{"true", "false", "yes", "no", "on", "off", "1", "0"})
+
+	RegisterType(TypeDetector{Name: "json", Detect: detectJSON, Validate: validateJSON})
+	RegisterType(TypeDetector{Name: "boolean", Detect: detectBoolean, Validate: validateBoolean})
+	RegisterType(TypeDetector{Name: "port", Detect: detectPort, Validate: validatePort})
+	RegisterType(TypeDetector{Name: "integer", Detect: detectWith(validateInteger), Validate: validateInteger})
+	RegisterType(TypeDetector{Name: "float", Detect: detectWith(validateFloat), Validate: validateFloat})
+	RegisterType(TypeDetector{Name: "duration", Detect: detectDuration, Validate: validateDuration})
+	RegisterType(TypeDetector{Name: "uuid", Detect: detectWith(validateUUID), Validate: validateUUID})
+	RegisterType(TypeDetector{Name: "ipv4", Detect: detectWith(validateIPv4), Validate: validateIPv4})
+	RegisterType(TypeDetector{Name: "ipv6", Detect: detectWith(validateIPv6), Validate: validateIPv6})
+	RegisterType(TypeDetector{Name: "cidr", Detect: detectWith(validateCIDR), Validate: validateCIDR})
+	RegisterType(TypeDetector{Name: "email", Detect: detectWith(validateEmail), Validate: validateEmail})
+	RegisterType(TypeDetector{Name: "url", Detect: detectWith(validateURL), Validate: validateURL})
+	RegisterType(TypeDetector{Name: "list", Detect: detectList, Validate: validateList})
+	RegisterType(TypeDetector{Name: "string", Detect: func(string, string) bool { return true }, Validate: func(string) error { return nil }})
+}
+
+// RegisterType adds a detector to the registry. A detector with the same name is replaced in place,
+// a new one is inserted just before the catch-all string type
+func RegisterType(detector TypeDetector) {
+	for i, existing := range registry {
+		if existing.Name == detector.Name {
+			registry[i] = detector
+			return
+		}
+	}
+	if n := len(registry); n > 0 && registry[n-1].Name == "string" {
+		registry = append(registry[:n-1], detector, registry[n-1])
+		return
+	}
+	registry = append(registry, detector)
+}
+
+// LookupType returns the detector registered under name
+func LookupType(name string) (TypeDetector, bool) {
+	for _, detector := range registry {
+		if detector.Name == name {
+			return detector, true
+		}
+	}
+	return TypeDetector{}, false
+}
+
+// SetBooleanValues replaces the words accepted as booleans (matched case-insensitively)
+func SetBooleanValues(values []string) {
+	booleanValues = make(map[string]bool, len(values))
+	for _, v := range values {
+		booleanValues[strings.ToLower(strings.TrimSpace(v))] = true
+	}
+}
+
+func detectWith(validate func(string) error) func(string, string) bool {
+	return func(_, value string) bool {
+		return validate(value) == nil
+	}
+}
+
+func detectJSON(_, value string) bool {
+	// only objects and arrays, otherwise every number and "true" would be json
+	if !strings.HasPrefix(value, "{") && !strings.HasPrefix(value, "[") {
+		return false
+	}
+	return validateJSON(value) == nil
+}
+
+func validateJSON(value string) error {
+	if !json.Valid([]byte(value)) {
+		return fmt.Errorf("not valid JSON")
+	}
+	return nil
+}
+
+func detectBoolean(_, value string) bool {
+	// 1 and 0 are accepted by the validator, but on their own they read better as integers
+	if validateInteger(value) == nil {
+		return false
+	}
+	return validateBoolean(value) == nil
+}
+
+func validateBoolean(value string) error {
+	if !booleanValues[strings.ToLower(value)] {
+		return fmt.Errorf("not a recognised boolean")
+	}
+	return nil
+}
+
+// PORT has to be a whole part of the key, so REPORT_INTERVAL or EXPORT_LIMIT aren't ports
+func detectPort(key, value string) bool {
+	return slices.Contains(strings.Split(strings.ToUpper(key), "_"), "PORT") && validatePort(value) == nil
+}
+
+func validatePort(value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("not an integer")
+	}
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port %d is outside 1-65535", port)
+	}
+	return nil
+}
+
+func validateInteger(value string) error {
+	if _, err := strconv.Atoi(value); err != nil {
+		return fmt.Errorf("not an integer")
+	}
+	return nil
+}
+
+func validateFloat(value string) error {
+	// ParseFloat also takes hex floats like 0x1p-2, nobody writes those in a .env on purpose
+	if strings.ContainsAny(value, "xX") {
+		return fmt.Errorf("not a number")
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("not a number")
+	}
+	// and NaN, Inf and Infinity, which are words rather than numbers here
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return fmt.Errorf("not a finite number")
+	}
+	return nil
+}
+
+func detectDuration(_, value string) bool {
+	// a bare number parses as a duration only when it is 0, leave numbers to the numeric types
+	if validateFloat(value) == nil {
+		return false
+	}
+	return validateDuration(value) == nil
+}
+
+func validateDuration(value string) error {
+	if _, err := time.ParseDuration(value); err != nil {
+		return fmt.Errorf("not a duration like 30s or 1h30m")
+	}
+	return nil
+}
+
+func validateUUID(value string) error {
+	if !uuidRegex.MatchString(value) {
+		return fmt.Errorf("not a UUID")
+	}
+	return nil
+}
+
+func validateIPv4(value string) error {
+	addr, err := netip.ParseAddr(value)
+	if err != nil || !addr.Is4() {
+		return fmt.Errorf("not an IPv4 address")
+	}
+	return nil
+}
+
+func validateIPv6(value string) error {
+	addr, err := netip.ParseAddr(value)
+	if err != nil || !addr.Is6() {
+		return fmt.Errorf("not an IPv6 address")
+	}
+	return nil
+}
+
+func validateCIDR(value string) error {
+	if _, err := netip.ParsePrefix(value); err != nil {
+		return fmt.Errorf("not a CIDR range")
+	}
+	return nil
+}
+
+func validateEmail(value string) error {
+	// ParseAddress also accepts "Name <addr>", we only want the bare address
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return fmt.Errorf("not an email address")
+	}
+	return nil
+}
+
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("not an absolute URL")
+	}
+	return nil
+}
+
+func detectList(_, value string) bool {
+	return strings.Contains(value, ",") && validateList(value) == nil
+}
+
+func validateList(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			return fmt.Errorf("list has an empty item")
+		}
+	}
+	return nil
+}
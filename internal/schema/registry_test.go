@@ -0,0 +1,72 @@
+package schema
+
+import "testing"
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		key, value, want string
+	}{
+		{"DEBUG", "true", "boolean"},
+		{"WORKERS", "4", "integer"},
+		{"RATIO", "0.75", "float"},
+		{"RATIO", "1e-3", "float"},
+		{"TIMEOUT", "30s", "duration"},
+		{"DB_PORT", "5432", "port"},
+		{"PORT", "80", "port"},
+		{"REPORT_INTERVAL", "60", "integer"}, // PORT inside another word isn't a port
+		{"EXPORT_LIMIT", "0", "integer"},
+		{"CONFIG", `{"a":1}`, "json"},
+		{"HOSTS", "a,b,c", "list"},
+		{"HOME_URL", "https://example.com", "url"},
+
+		// words and hex floats ParseFloat would take
+		{"LIMIT", "Infinity", "string"},
+		{"LIMIT", "inf", "string"},
+		{"LIMIT", "-Inf", "string"},
+		{"VALUE", "nan", "string"},
+		{"VALUE", "NaN", "string"},
+		{"VALUE", "0x1p-2", "string"},
+	}
+	for _, tt := range tests {
+		if got := InferType(tt.key, tt.value); got != tt.want {
+			t.Errorf("InferType(%s, %q) = %s, want %s", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTypes(t *testing.T) {
+	tests := []struct {
+		typ, value string
+		ok         bool
+	}{
+		{"float", "3.14", true},
+		{"float", "-2", true},
+		{"float", "1e10", true},
+		{"float", "NaN", false},
+		{"float", "nan", false},
+		{"float", "Inf", false},
+		{"float", "+Infinity", false},
+		{"float", "infinity", false},
+		{"float", "0x1p-2", false},
+		{"float", "0X1P+4", false},
+		{"float", "1e400", false},
+		{"integer", "42", true},
+		{"integer", "4.2", false},
+		{"port", "65535", true},
+		{"port", "0", false},
+		{"port", "65536", false},
+		{"boolean", "yes", true},
+		{"boolean", "maybe", false},
+		{"duration", "1h30m", true},
+		{"duration", "90", false},
+	}
+	for _, tt := range tests {
+		detector, ok := LookupType(tt.typ)
+		if !ok {
+			t.Fatalf("type %s isn't registered", tt.typ)
+		}
+		if err := detector.Validate(tt.value); (err == nil) != tt.ok {
+			t.Errorf("%s.Validate(%q) = %v, want ok=%v", tt.typ, tt.value, err, tt.ok)
+		}
+	}
+}
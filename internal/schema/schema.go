@@ -3,7 +3,8 @@ package schema
 import (
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/adnaneAkk/envdoc/internal/secrets"
@@ -12,24 +13,36 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// Generate creates a schema from the parsed environment variables
-func Generate(envVarMap types.EnvVarMap, config types.Config) types.Schema {
+// Generate creates a schema from the parsed environment variables.
+// When existing is non-nil, hand-written fields (required, description and constraints) are carried over
+func Generate(envVarMap types.EnvVarMap, existing types.Schema, config types.Config, detector *secrets.Detector) types.Schema {
 	schema := types.Schema{}
 
 	for key, item := range envVarMap {
-		valueType := guessValueType(item.Value)
+		valueType := InferType(key, item.Value)
 
 		finalValue := item.Value
-		isSensitive := secrets.IsRedacted(key, item.Value)
+		isSensitive := detector.IsRedacted(key, item.Value)
 		if isSensitive && !config.Unmask {
 			finalValue = "[SENSITIVE]"
 		}
-		schema[key] = types.SchemaItem{
+		schemaItem := types.SchemaItem{
 			Value:     finalValue,
 			Type:      valueType,
 			Required:  false,
 			Sensitive: isSensitive,
 		}
+		if previous, ok := existing[key]; ok {
+			schemaItem.Required = previous.Required
+			schemaItem.Description = previous.Description
+			schemaItem.Enum = previous.Enum
+			schemaItem.Min = previous.Min
+			schemaItem.Max = previous.Max
+			schemaItem.Pattern = previous.Pattern
+			schemaItem.MinLength = previous.MinLength
+			schemaItem.MaxLength = previous.MaxLength
+		}
+		schema[key] = schemaItem
 	}
 
 	return schema
@@ -60,6 +73,27 @@ func Output(schema types.Schema, format string) (string, error) {
 			sb.WriteString(fmt.Sprintf("  type: %s\n", item.Type))
 			sb.WriteString(fmt.Sprintf("  required: %v\n", item.Required))
 			sb.WriteString(fmt.Sprintf("  sensitive: %v\n", item.Sensitive))
+			if item.Description != "" {
+				sb.WriteString(fmt.Sprintf("  description: %s\n", item.Description))
+			}
+			if len(item.Enum) > 0 {
+				sb.WriteString(fmt.Sprintf("  enum: %s\n", strings.Join(item.Enum, ", ")))
+			}
+			if item.Min != nil {
+				sb.WriteString(fmt.Sprintf("  min: %v\n", *item.Min))
+			}
+			if item.Max != nil {
+				sb.WriteString(fmt.Sprintf("  max: %v\n", *item.Max))
+			}
+			if item.Pattern != "" {
+				sb.WriteString(fmt.Sprintf("  pattern: %s\n", item.Pattern))
+			}
+			if item.MinLength != nil {
+				sb.WriteString(fmt.Sprintf("  minLength: %d\n", *item.MinLength))
+			}
+			if item.MaxLength != nil {
+				sb.WriteString(fmt.Sprintf("  maxLength: %d\n", *item.MaxLength))
+			}
 			sb.WriteString("\n")
 		}
 		return sb.String(), nil
@@ -69,17 +103,34 @@ func Output(schema types.Schema, format string) (string, error) {
 	}
 }
 
-func guessValueType(value string) string {
-	value = strings.TrimSpace(value)
+// Load reads a schema previously written by Output, picking the decoder from the file extension
+func Load(filename string) (types.Schema, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening schema %s: %v", filename, err)
+	}
 
-	if value == "true" || value == "false" {
-		return "boolean"
+	schema := types.Schema{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &schema)
+	default:
+		err = json.Unmarshal(data, &schema)
 	}
-	if _, err := strconv.Atoi(value); err == nil {
-		return "integer"
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema %s: %v", filename, err)
 	}
-	if _, err := strconv.ParseFloat(value, 64); err == nil {
-		return "float"
+	return schema, nil
+}
+
+// InferType guesses the schema type of a value, the first registered type whose detector matches wins
+func InferType(key, value string) string {
+	value = strings.TrimSpace(value)
+
+	for _, detector := range registry {
+		if detector.Detect(key, value) {
+			return detector.Name
+		}
 	}
 	return "string"
 }
@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adnaneAkk/envdoc/internal/lint"
+	"github.com/adnaneAkk/envdoc/internal/types"
+)
+
+// Validate checks the parsed environment variables against a schema and returns errors and warnings
+func Validate(envVarMap types.EnvVarMap, schema types.Schema, config types.Config) ([]types.Issue, []types.Issue) {
+	var errors []types.Issue
+	var warnings []types.Issue
+
+	// walk the schema in a stable order so the report doesn't shuffle between runs
+	keys := make([]string, 0, len(schema))
+	for key := range schema {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		item := schema[key]
+		envVar, exists := envVarMap[key]
+
+		if !exists {
+			if item.Required {
+				lint.Add("ED101", types.Issue{
+					LineNum: 0,
+					Message: "required key is missing",
+					KeyName: key,
+				}, config.Strict, &errors, &warnings)
+			}
+			continue
+		}
+
+		if envVar.Value == "" {
+			if item.Required {
+				add("ED101", envVar, types.Issue{
+					LineNum: envVar.LineNum,
+					Message: "required key has an empty value",
+					KeyName: key,
+					Span:    envVar.KeySpan,
+				}, config.Strict, &errors, &warnings)
+			}
+			continue
+		}
+
+		known, typeErr := checkType(envVar.Value, item.Type)
+		if !known {
+			add("ED102", envVar, types.Issue{
+				LineNum: envVar.LineNum,
+				Message: fmt.Sprintf("schema declares unknown type %q", item.Type),
+				KeyName: key,
+				Span:    envVar.KeySpan,
+			}, config.Strict, &errors, &warnings)
+		} else if typeErr != nil {
+			add("ED103", envVar, types.Issue{
+				LineNum: envVar.LineNum,
+				Message: fmt.Sprintf("value does not match schema type %s: %v", item.Type, typeErr),
+				KeyName: key,
+				Span:    envVar.ValueSpan,
+			}, config.Strict, &errors, &warnings)
+		}
+
+		checkConstraints(key, envVar, item, config, &errors, &warnings)
+	}
+
+	// keys in the file that the schema knows nothing about
+	var undeclared []string
+	for key := range envVarMap {
+		if _, exists := schema[key]; !exists {
+			undeclared = append(undeclared, key)
+		}
+	}
+	sort.Strings(undeclared)
+
+	for _, key := range undeclared {
+		add("ED104", envVarMap[key], types.Issue{
+			LineNum: envVarMap[key].LineNum,
+			Message: "key is not declared in the schema",
+			KeyName: key,
+			Span:    envVarMap[key].KeySpan,
+		}, config.Strict, &errors, &warnings)
+	}
+
+	return errors, warnings
+}
+
+// add files an issue about envVar unless an envdoc: comment on its lines silences the rule
+func add(rule string, envVar types.EnvVar, issue types.Issue, strict bool, errors, warnings *[]types.Issue) {
+	if slices.Contains(envVar.Ignore, rule) {
+		return
+	}
+	lint.Add(rule, issue, strict, errors, warnings)
+}
+
+// checkConstraints enforces the optional schema constraints, one issue per rule that fails
+func checkConstraints(key string, envVar types.EnvVar, item types.SchemaItem, config types.Config, errors, warnings *[]types.Issue) {
+	value := envVar.Value
+
+	fail := func(rule, message string) {
+		add(rule, envVar, types.Issue{
+			LineNum: envVar.LineNum,
+			Message: message,
+			KeyName: key,
+			Span:    envVar.ValueSpan,
+		}, config.Strict, errors, warnings)
+	}
+
+	if len(item.Enum) > 0 && !slices.Contains(item.Enum, value) {
+		fail("ED105", fmt.Sprintf("value must be one of: %s", strings.Join(item.Enum, ", ")))
+	}
+
+	// min and max only make sense for numbers, a non-numeric value is already a type issue
+	if item.Min != nil || item.Max != nil {
+		if number, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			if item.Min != nil && number < *item.Min {
+				fail("ED106", fmt.Sprintf("value %v is below the minimum %v", number, *item.Min))
+			}
+			if item.Max != nil && number > *item.Max {
+				fail("ED107", fmt.Sprintf("value %v is above the maximum %v", number, *item.Max))
+			}
+		}
+	}
+
+	if item.Pattern != "" {
+		re, err := regexp.Compile(item.Pattern)
+		if err != nil {
+			fail("ED108", fmt.Sprintf("schema pattern %q is invalid: %v", item.Pattern, err))
+		} else if !re.MatchString(value) {
+			fail("ED108", fmt.Sprintf("value does not match pattern %s", item.Pattern))
+		}
+	}
+
+	length := utf8.RuneCountInString(value)
+	if item.MinLength != nil && length < *item.MinLength {
+		fail("ED109", fmt.Sprintf("value is %d characters, shorter than the minimum %d", length, *item.MinLength))
+	}
+	if item.MaxLength != nil && length > *item.MaxLength {
+		fail("ED110", fmt.Sprintf("value is %d characters, longer than the maximum %d", length, *item.MaxLength))
+	}
+}
+
+// checkType validates value against the registered type, the bool is false when typ isn't registered
+func checkType(value, typ string) (bool, error) {
+	if typ == "" {
+		return true, nil
+	}
+	detector, ok := LookupType(typ)
+	if !ok {
+		return false, nil
+	}
+	return true, detector.Validate(strings.TrimSpace(value))
+}
@@ -0,0 +1,147 @@
+package secrets
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// BaselineFile is where envdoc keeps accepted secrets by default, next to .envdoc.yaml
+const BaselineFile = ".envdoc-baseline.json"
+
+// baselineVersion changes if the way values are hashed ever does
+const baselineVersion = 1
+
+// Baseline records secrets someone has looked at and accepted, so scans only fail on new ones.
+// Values are only ever stored as an HMAC-SHA256 keyed with the baseline's own random salt
+type Baseline struct {
+	Version int             `json:"version"`
+	Salt    string          `json:"salt"`
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry is one accepted secret
+type BaselineEntry struct {
+	File string `json:"file"` // relative to the baseline, with forward slashes
+	Key  string `json:"key"`
+	Line int    `json:"line"` // where it was when accepted, only for people reading the file
+	Rule string `json:"rule"` // what detected it, a pattern name or entropy
+	Hash string `json:"hash"`
+}
+
+// NewBaseline starts an empty baseline with a fresh salt
+func NewBaseline() (*Baseline, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &Baseline{Version: baselineVersion, Salt: hex.EncodeToString(salt), Entries: []BaselineEntry{}}, nil
+}
+
+// LoadBaseline reads a baseline written by Save
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening baseline %s: %v", path, err)
+	}
+	b := &Baseline{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("error reading baseline %s: %v", path, err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("baseline %s has version %d, this envdoc reads version %d", path, b.Version, baselineVersion)
+	}
+	if b.Salt == "" {
+		return nil, fmt.Errorf("baseline %s has no salt", path)
+	}
+	return b, nil
+}
+
+// Save writes the baseline with its entries sorted, so it diffs cleanly in review
+func (b *Baseline) Save(path string) error {
+	sortEntries(b.Entries)
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Hash is what the baseline stores instead of value
+func (b *Baseline) Hash(value string) string {
+	mac := hmac.New(sha256.New, []byte(b.Salt))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Entry makes the entry for a secret found now, hashed with this baseline's salt
+func (b *Baseline) Entry(file, key string, line int, rule, value string) BaselineEntry {
+	return BaselineEntry{File: file, Key: key, Line: line, Rule: rule, Hash: b.Hash(value)}
+}
+
+// Accepts reports whether the baseline has this exact value for the key in the file
+func (b *Baseline) Accepts(file, key, value string) bool {
+	hash := b.Hash(value)
+	for _, e := range b.Entries {
+		if e.File == file && e.Key == key && hmac.Equal([]byte(e.Hash), []byte(hash)) {
+			return true
+		}
+	}
+	return false
+}
+
+// BaselineDiff sorts the secrets found now against what a baseline accepted
+type BaselineDiff struct {
+	Kept    []BaselineEntry // accepted and still the same
+	Changed []BaselineEntry // accepted, but the value has changed since
+	Added   []BaselineEntry // never accepted
+	Gone    []BaselineEntry // accepted, but no longer found
+}
+
+// CompareBaseline matches the secrets found now, hashed with Entry, against accepted entries by file and key
+func CompareBaseline(accepted, current []BaselineEntry) BaselineDiff {
+	type id struct{ file, key string }
+	byID := map[id]BaselineEntry{}
+	for _, e := range accepted {
+		byID[id{e.File, e.Key}] = e
+	}
+
+	var diff BaselineDiff
+	seen := map[id]bool{}
+	for _, e := range current {
+		k := id{e.File, e.Key}
+		seen[k] = true
+		old, ok := byID[k]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, e)
+		case old.Hash != e.Hash:
+			diff.Changed = append(diff.Changed, e)
+		default:
+			diff.Kept = append(diff.Kept, e)
+		}
+	}
+	for _, e := range accepted {
+		if !seen[id{e.File, e.Key}] {
+			diff.Gone = append(diff.Gone, e)
+		}
+	}
+	for _, list := range [][]BaselineEntry{diff.Kept, diff.Changed, diff.Added, diff.Gone} {
+		sortEntries(list)
+	}
+	return diff
+}
+
+func sortEntries(entries []BaselineEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].File != entries[j].File {
+			return entries[i].File < entries[j].File
+		}
+		return entries[i].Key < entries[j].Key
+	})
+}
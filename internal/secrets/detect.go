@@ -1,82 +1,159 @@
 package secrets
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"math"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 )
 
-func IsSensitiveKey(key string) bool {
-	lowerKey := strings.ToLower(key)
+// Strong signals: substring match is fine, these are unambiguous
+var strongKeywords = []string{
+	"password", "passwd", "secret", "private_key",
+	"client_secret", "access_token", "refresh_token",
+	"id_token", "auth_token", "api_token", "bearer",
+	"credential", "certificate", "encryption_key",
+	"signing_key", "hmac", "oauth",
+}
+
+// weak signals only count as a whole part of the key, so KEY matches API_KEY but not MONKEY
+var weakKeywords = []string{
+	"key", "secret", "token",
+	"auth", "jwt", "cert",
+	"pem", "pkcs", "sig",
+	"pwd", "pass", "pin",
+	"seed", "salt", "nonce",
+	"session", "cookie",
+}
+
+// FingerprintPrefix marks an allow-listed value given as its SHA-256 rather than in plain text
+const FingerprintPrefix = "sha256:"
+
+// Rules adjust the built-in detection, they come from the secrets section of .envdoc.yaml
+type Rules struct {
+	Keywords       []string          `yaml:"keywords"`        // extra key words, matched as whole parts of the key
+	IgnoreKeywords []string          `yaml:"ignore-keywords"` // built-in key words to drop, like pin or session
+	Patterns       map[string]string `yaml:"patterns"`        // extra value regexes by name
+	IgnorePatterns []string          `yaml:"ignore-patterns"` // built-in value regexes to drop, by name
+	AllowKeys      []string          `yaml:"allow-keys"`      // keys that are never sensitive
+	AllowValues    []string          `yaml:"allow-values"`    // values that are never sensitive, or their sha256:<hex> fingerprints
+}
+
+// Detector decides what counts as a secret. Build one with New, the zero value detects nothing
+type Detector struct {
+	strong        []string
+	weak          map[string]bool
+	keywords      []string    // from Rules.Keywords, lower case
+	patterns      []Signature // the catalogue minus ignored ones, then the configured ones
+	allowedKeys   map[string]bool
+	allowedValues map[string]bool // plain values and fingerprints alike
+	fingerprints  bool            // whether allowedValues has any fingerprints, so values need hashing
+}
+
+// Default detects with the built-in keywords and patterns only
+func Default() *Detector {
+	d, _ := New(Rules{}) // the built-in patterns always compile
+	return d
+}
 
-	// Strong signals: substring match is fine, these are unambiguous
-	strongSignals := []string{
-		"password", "passwd", "secret", "private_key",
-		"client_secret", "access_token", "refresh_token",
-		"id_token", "auth_token", "api_token", "bearer",
-		"credential", "certificate", "encryption_key",
-		"signing_key", "hmac", "oauth",
+// New compiles the built-in rules adjusted by rules. It fails on a regex that doesn't compile
+// and on ignoring a built-in pattern that doesn't exist, which is likely a typo
+func New(rules Rules) (*Detector, error) {
+	d := &Detector{
+		weak:          map[string]bool{},
+		allowedKeys:   map[string]bool{},
+		allowedValues: map[string]bool{},
 	}
-	for _, word := range strongSignals {
-		if strings.Contains(lowerKey, word) {
-			return true
+
+	ignored := map[string]bool{}
+	for _, word := range rules.IgnoreKeywords {
+		ignored[strings.ToLower(word)] = true
+	}
+	for _, word := range strongKeywords {
+		if !ignored[word] {
+			d.strong = append(d.strong, word)
 		}
 	}
-
-	parts := strings.Split(lowerKey, "_")
-	partSet := make(map[string]bool, len(parts))
-	for _, p := range parts {
-		partSet[p] = true
+	for _, word := range weakKeywords {
+		if !ignored[word] {
+			d.weak[word] = true
+		}
 	}
-
-	weakSignals := map[string]bool{
-		"key": true, "secret": true, "token": true,
-		"auth": true, "jwt": true, "cert": true,
-		"pem": true, "pkcs": true, "sig": true,
-		"pwd": true, "pass": true, "pin": true,
-		"seed": true, "salt": true, "nonce": true,
-		"session": true, "cookie": true,
+	for _, word := range rules.Keywords {
+		d.keywords = append(d.keywords, strings.ToLower(word))
 	}
 
-	for _, part := range parts {
-		if weakSignals[part] {
-			return true
+	ignoredPatterns := map[string]bool{}
+	for _, id := range rules.IgnorePatterns {
+		if !slices.Contains(PatternNames(), id) {
+			return nil, fmt.Errorf("unknown secret pattern %s (built-in: %s)", id, strings.Join(PatternNames(), ", "))
 		}
+		ignoredPatterns[id] = true
 	}
-
-	return false
-}
-
-func IsSensitiveValue(value string) bool {
-	if len(value) == 0 {
-		return false
+	// a configured pattern with a built-in's name takes its place
+	for _, signature := range Signatures {
+		if !ignoredPatterns[signature.ID] && rules.Patterns[signature.ID] == "" {
+			d.patterns = append(d.patterns, signature)
+		}
 	}
-
-	// if the entropy is high,then yeah its probably a secret (API keys, tokens, hashes)
-	if len(value) >= 16 && shannonEntropy(value) > 4.5 {
-		return true
+	names := make([]string, 0, len(rules.Patterns))
+	for name := range rules.Patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(rules.Patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("secret pattern %s: %v", name, err)
+		}
+		d.patterns = append(d.patterns, Signature{ID: name, Provider: "custom", Pattern: re})
 	}
 
-	// these are regex for Known secret formats
-	patterns := []*regexp.Regexp{
-		regexp.MustCompile(`^[A-Za-z0-9+/]{40,}={0,2}$`),           // base64 blobs
-		regexp.MustCompile(`^[0-9a-fA-F]{32,}$`),                   // hex hashes/tokens
-		regexp.MustCompile(`-----BEGIN [A-Z ]+-----`),              // PEM keys
-		regexp.MustCompile(`^(ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]+$`), // GitHub tokens
-		regexp.MustCompile(`^sk-[A-Za-z0-9]{32,}$`),                // OpenAI keys
-		regexp.MustCompile(`^[A-Z0-9]{20}:[A-Za-z0-9_-]{40}$`),     // AWS-style keys
-		regexp.MustCompile(`postgres://[^:]+:[^@]+@`),              // DSNs with credentials
-		regexp.MustCompile(`^sk_(live|test|prod)_[A-Za-z0-9]+$`),   // Stripe-style keys
-		regexp.MustCompile(`mysql://[^:]+:[^@]+@`),
-		regexp.MustCompile(`mongodb(\+srv)?://[^:]+:[^@]+@`),
+	for _, key := range rules.AllowKeys {
+		d.allowedKeys[strings.ToLower(key)] = true
 	}
-	for _, re := range patterns {
-		if re.MatchString(value) {
-			return true
+	for _, value := range rules.AllowValues {
+		if fingerprint, ok := strings.CutPrefix(value, FingerprintPrefix); ok {
+			value = FingerprintPrefix + strings.ToLower(fingerprint)
+			d.fingerprints = true
 		}
+		d.allowedValues[value] = true
 	}
+	return d, nil
+}
+
+// PatternNames lists the IDs of the built-in signatures, in catalogue order
+func PatternNames() []string {
+	names := make([]string, len(Signatures))
+	for i, signature := range Signatures {
+		names[i] = signature.ID
+	}
+	return names
+}
+
+// Fingerprint is what allow-values takes instead of a value that shouldn't be written down
+func Fingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return FingerprintPrefix + hex.EncodeToString(sum[:])
+}
 
-	return false
+// IsSensitiveKey says whether the key name alone makes a value sensitive
+func (d *Detector) IsSensitiveKey(key string) bool {
+	return len(d.keyFindings(key)) > 0
+}
+
+// IsSensitiveValue says whether the value looks like a secret whatever its key is called
+func (d *Detector) IsSensitiveValue(value string) bool {
+	return len(d.ValueFindings(value)) > 0
+}
+
+// allowed checks the value itself and then its fingerprint, hashing only when there are fingerprints
+func (d *Detector) allowed(value string) bool {
+	return d.allowedValues[value] || (d.fingerprints && d.allowedValues[Fingerprint(value)])
 }
 
 func shannonEntropy(s string) float64 {
@@ -92,6 +169,18 @@ func shannonEntropy(s string) float64 {
 	}
 	return entropy
 }
-func IsRedacted(key, value string) bool {
-	return IsSensitiveKey(key) || IsSensitiveValue(value)
+
+// IsRedacted says whether a key's value should be hidden, an allow-listed key never is
+func (d *Detector) IsRedacted(key, value string) bool {
+	return len(d.Findings(key, value)) > 0
+}
+
+// AllowedKey says whether allow-keys lists the key
+func (d *Detector) AllowedKey(key string) bool {
+	return d.allowedKeys[strings.ToLower(key)]
+}
+
+// AllowedValue says whether allow-values lists the value or its fingerprint
+func (d *Detector) AllowedValue(value string) bool {
+	return d.allowed(value)
 }
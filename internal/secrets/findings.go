@@ -0,0 +1,136 @@
+package secrets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Detector IDs, the reason a finding was raised
+const (
+	KeyKeyword    = "key-keyword"    // the key contains a strong word like password
+	CustomKeyword = "custom-keyword" // the key has a word from the keywords config
+	ValuePattern  = "value-pattern"  // the value has a known secret format
+	KeyPart       = "key-part"       // a part of the key is a weak word like key or token
+	Entropy       = "entropy"        // the value is long and random looking
+)
+
+// how sure each detector is, a strong key word beats a known format which beats entropy
+var confidence = map[string]float64{
+	KeyKeyword:    0.95,
+	CustomKeyword: 0.9,
+	ValuePattern:  0.8,
+	KeyPart:       0.6,
+	Entropy:       0.5,
+}
+
+// the entropy detector's thresholds
+const (
+	minEntropyLength = 16
+	minEntropy       = 4.5
+)
+
+// Finding is one reason a value counts as sensitive. Explanations never quote the value
+type Finding struct {
+	Detector    string  `json:"detector"`
+	Rule        string  `json:"rule,omitempty"`     // the word or pattern name that matched
+	Provider    string  `json:"provider,omitempty"` // whose format a value pattern is, "custom" for configured ones
+	In          string  `json:"in"`                 // "key" or "value"
+	Start       int     `json:"start"`              // byte offsets of the match in the key or value, End exclusive
+	End         int     `json:"end"`
+	Confidence  float64 `json:"confidence"`
+	Explanation string  `json:"explanation"`
+}
+
+// Findings lists every reason the key's value is sensitive, most confident first.
+// It's empty when nothing matched or the key is allow-listed
+func (d *Detector) Findings(key, value string) []Finding {
+	if d.AllowedKey(key) {
+		return nil
+	}
+	findings := append(d.keyFindings(key), d.ValueFindings(value)...)
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Confidence > findings[j].Confidence
+	})
+	return findings
+}
+
+func (d *Detector) keyFindings(key string) []Finding {
+	lowerKey := strings.ToLower(key)
+	if d.allowedKeys[lowerKey] {
+		return nil
+	}
+
+	var findings []Finding
+	// a match inside an earlier one adds nothing, CLIENT_SECRET is explained by client_secret alone
+	covered := func(start, end int) bool {
+		for _, f := range findings {
+			if start >= f.Start && end <= f.End {
+				return true
+			}
+		}
+		return false
+	}
+	add := func(detector, word string, start int, explanation string) {
+		if end := start + len(word); !covered(start, end) {
+			findings = append(findings, Finding{
+				Detector: detector, Rule: word, In: "key", Start: start, End: end,
+				Confidence: confidence[detector], Explanation: explanation,
+			})
+		}
+	}
+
+	strong := append([]string(nil), d.strong...)
+	sort.SliceStable(strong, func(i, j int) bool { return len(strong[i]) > len(strong[j]) })
+	for _, word := range strong {
+		if i := strings.Index(lowerKey, word); i >= 0 {
+			add(KeyKeyword, word, i, fmt.Sprintf("the key contains %q", word))
+		}
+	}
+
+	// custom keywords may span parts themselves, like acme_tkn
+	padded := "_" + lowerKey + "_"
+	for _, word := range d.keywords {
+		if i := strings.Index(padded, "_"+word+"_"); i >= 0 {
+			add(CustomKeyword, word, i, fmt.Sprintf("the key has the configured keyword %q", word))
+		}
+	}
+
+	offset := 0
+	for _, part := range strings.Split(lowerKey, "_") {
+		if d.weak[part] {
+			add(KeyPart, part, offset, fmt.Sprintf("%q is a part of the key", part))
+		}
+		offset += len(part) + 1
+	}
+
+	return findings
+}
+
+// ValueFindings lists the reasons the value alone looks like a secret, whatever its key is called
+func (d *Detector) ValueFindings(value string) []Finding {
+	if len(value) == 0 || d.allowed(value) {
+		return nil
+	}
+
+	var findings []Finding
+	for _, signature := range d.patterns {
+		if loc := signature.Matches(value); loc != nil {
+			findings = append(findings, Finding{
+				Detector: ValuePattern, Rule: signature.ID, Provider: signature.Provider, In: "value", Start: loc[0], End: loc[1],
+				Confidence:  confidence[ValuePattern],
+				Explanation: fmt.Sprintf("the value matches the %s pattern (%s)", signature.ID, signature.Provider),
+			})
+		}
+	}
+
+	// if the entropy is high,then yeah its probably a secret (API keys, tokens, hashes)
+	if entropy := shannonEntropy(value); len(value) >= minEntropyLength && entropy > minEntropy {
+		findings = append(findings, Finding{
+			Detector: Entropy, In: "value", Start: 0, End: len(value),
+			Confidence:  confidence[Entropy],
+			Explanation: fmt.Sprintf("the value has %.2f bits of entropy per character, more than %.1f over %d characters looks random", entropy, minEntropy, len(value)),
+		})
+	}
+	return findings
+}
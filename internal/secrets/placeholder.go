@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// values people put in templates instead of the real thing
+var placeholders = map[string]bool{
+	"changeme": true, "change-me": true, "change_me": true, "changethis": true,
+	"replaceme": true, "replace-me": true, "replace_me": true,
+	"todo": true, "tbd": true, "fixme": true, "none": true, "null": true, "n/a": true,
+	"placeholder": true, "example": true, "sample": true, "dummy": true, "fake": true,
+	"secret": true, "password": true, "test": true, "redacted": true, "[sensitive]": true,
+}
+
+// words that give a value away as made up when they stand on their own in it, like sk_test_example
+// or my-dummy-token. change_me and change-me are two words, changeme is checked against pairs too
+var placeholderWords = map[string]bool{"changeme": true, "placeholder": true, "example": true, "dummy": true, "redacted": true}
+
+// IsPlaceholder recognises values that stand in for a secret rather than being one:
+// changeme, xxx, ****, <your-key>, {{ token }}, ${SECRET}, your_api_key_here and the like
+func IsPlaceholder(value string) bool {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if v == "" || placeholders[v] {
+		return true
+	}
+
+	// the same character over and over: xxx, ****, 0000
+	if len(v) >= 3 && strings.Count(v, v[:1]) == len(v) {
+		return true
+	}
+
+	for _, wrap := range [][2]string{{"<", ">"}, {"{{", "}}"}, {"${", "}"}, {"[", "]"}, {"%", "%"}} {
+		if len(v) > len(wrap[0])+len(wrap[1]) && strings.HasPrefix(v, wrap[0]) && strings.HasSuffix(v, wrap[1]) {
+			return true
+		}
+	}
+
+	if strings.HasPrefix(v, "your_") || strings.HasPrefix(v, "your-") || strings.HasPrefix(v, "your ") {
+		return true
+	}
+	// a URL says nothing by its host, db.example.com may well sit behind a real password.
+	// The password is what a placeholder would stand in for
+	if u, err := url.Parse(v); err == nil && u.Host != "" {
+		if password, ok := u.User.Password(); ok {
+			return IsPlaceholder(password)
+		}
+		u.Host = ""
+		v = u.String()
+	}
+
+	words := strings.FieldsFunc(v, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for i, word := range words {
+		if placeholderWords[word] || (i+1 < len(words) && placeholderWords[word+words[i+1]]) {
+			return true
+		}
+		// sk_live_xxxxxxxx
+		if len(word) >= 4 && strings.Count(word, "x") == len(word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"hash/crc32"
+	"regexp"
+	"strings"
+)
+
+// Signature is a known secret format. ID is what findings and the ignore-patterns config use
+type Signature struct {
+	ID       string
+	Provider string
+	Pattern  *regexp.Regexp
+	// Validate checks a match further, a checksum for instance, nil accepts every match
+	Validate func(match string) bool
+}
+
+// Matches returns where the value has this secret in it, nil when it hasn't
+func (s Signature) Matches(value string) []int {
+	for _, loc := range s.Pattern.FindAllStringIndex(value, -1) {
+		if s.Validate == nil || s.Validate(value[loc[0]:loc[1]]) {
+			return loc
+		}
+	}
+	return nil
+}
+
+// Signatures is the catalogue of known formats, provider specific ones first so a finding
+// names the most precise one. Generic shapes like base64 and hex blobs come last
+var Signatures = []Signature{
+	// AWS access key IDs are a four letter type prefix and 16 base32 characters
+	{ID: "aws-access-key-id", Provider: "AWS", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|A3T[A-Z0-9])[A-Z2-7]{16}\b`)},
+
+	// classic GitHub tokens end in a CRC32 of the rest, which rules out made up ones like ghp_xxxx
+	{ID: "github-token", Provider: "GitHub", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36}\b`), Validate: githubChecksum},
+	{ID: "github-fine-grained-token", Provider: "GitHub", Pattern: regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9]{22}_[A-Za-z0-9]{59}\b`)},
+
+	{ID: "slack-token", Provider: "Slack", Pattern: regexp.MustCompile(`\bxox[abposr]-(?:[0-9]+-)+[A-Za-z0-9]+\b`)},
+	{ID: "slack-webhook", Provider: "Slack", Pattern: regexp.MustCompile(`https://hooks\.slack\.com/(?:services|workflows)/T[A-Z0-9]+/[AB][A-Z0-9]+/[A-Za-z0-9]+`)},
+
+	{ID: "google-api-key", Provider: "Google", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{ID: "google-oauth-secret", Provider: "Google", Pattern: regexp.MustCompile(`\bGOCSPX-[A-Za-z0-9_-]{28}`)},
+
+	{ID: "twilio-api-key", Provider: "Twilio", Pattern: regexp.MustCompile(`\bSK[0-9a-f]{32}\b`)},
+
+	{ID: "sendgrid-api-key", Provider: "SendGrid", Pattern: regexp.MustCompile(`\bSG\.[A-Za-z0-9_-]{22}\.[A-Za-z0-9_-]{43}\b`)},
+
+	{ID: "stripe-key", Provider: "Stripe", Pattern: regexp.MustCompile(`\b(?:sk|rk)_(?:live|test|prod)_[A-Za-z0-9]{10,}\b`)},
+
+	{ID: "openai-key", Provider: "OpenAI", Pattern: regexp.MustCompile(`\bsk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_-]{32,}`)},
+
+	{ID: "azure-storage-key", Provider: "Azure", Pattern: regexp.MustCompile(`AccountKey=[A-Za-z0-9+/]{86}==`)},
+	{ID: "azure-client-secret", Provider: "Azure", Pattern: regexp.MustCompile(`[A-Za-z0-9_~.-]{3}[0-9]Q~[A-Za-z0-9_~.-]{31,34}`)},
+
+	{ID: "npm-token", Provider: "npm", Pattern: regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}\b`)},
+
+	// PyPI tokens are macaroons whose first bytes always encode pypi.org
+	{ID: "pypi-token", Provider: "PyPI", Pattern: regexp.MustCompile(`\bpypi-AgEIcHlwaS5vcmc[A-Za-z0-9_-]{50,}`)},
+
+	// the header has to decode to JSON naming an algorithm, so three dotted words aren't enough
+	{ID: "jwt", Provider: "JWT", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), Validate: jwtHeader},
+
+	{ID: "pem", Provider: "PEM", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]+-----`)},
+
+	// connection strings with a password in them
+	{ID: "postgres-dsn", Provider: "PostgreSQL", Pattern: regexp.MustCompile(`postgres(?:ql)?://[^:/@\s]+:[^@/\s]+@`)},
+	{ID: "mysql-dsn", Provider: "MySQL", Pattern: regexp.MustCompile(`mysql://[^:/@\s]+:[^@/\s]+@`)},
+	{ID: "mongodb-dsn", Provider: "MongoDB", Pattern: regexp.MustCompile(`mongodb(?:\+srv)?://[^:/@\s]+:[^@/\s]+@`)},
+	{ID: "redis-dsn", Provider: "Redis", Pattern: regexp.MustCompile(`rediss?://[^:/@\s]*:[^@/\s]+@`)},
+	{ID: "amqp-dsn", Provider: "AMQP", Pattern: regexp.MustCompile(`amqps?://[^:/@\s]+:[^@/\s]+@`)},
+	{ID: "url-credentials", Provider: "generic", Pattern: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^:/@\s]*:[^@/\s]+@`)},
+
+	{ID: "base64-blob", Provider: "generic", Pattern: regexp.MustCompile(`^[A-Za-z0-9+/]{40,}={0,2}$`)},
+	{ID: "hex-token", Provider: "generic", Pattern: regexp.MustCompile(`^[0-9a-fA-F]{32,}$`)},
+}
+
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// githubChecksum checks the last 6 characters of a token are the base62 CRC32 of the 30 before them
+func githubChecksum(token string) bool {
+	_, body, ok := strings.Cut(token, "_")
+	if !ok || len(body) != 36 {
+		return false
+	}
+	sum := crc32.ChecksumIEEE([]byte(body[:30]))
+	encoded := make([]byte, 6)
+	for i := len(encoded) - 1; i >= 0; i-- {
+		encoded[i] = base62[sum%62]
+		sum /= 62
+	}
+	return string(encoded) == body[30:]
+}
+
+// jwtHeader checks the first part of a token is a JWT header
+func jwtHeader(token string) bool {
+	header, _, _ := strings.Cut(token, ".")
+	data, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		return false
+	}
+	var fields struct {
+		Alg string `json:"alg"`
+	}
+	return json.Unmarshal(data, &fields) == nil && fields.Alg != ""
+}
@@ -5,26 +5,57 @@ type Config struct {
 	Strict         bool
 	GenerateSchema bool
 	Unmask         bool
+	Expand         bool   // resolve ${VAR} references after parsing
+	ProcessEnv     bool   // let references fall back to the process environment
+	Dialect        string // loader rules to parse with, empty means envdoc's own
 }
 
 // Issue struct for recording issues found in .env
 type Issue struct {
 	LineNum   int
-	IssueType string // for now there is : syntax, duplicate, strict, warning
+	IssueType string // name of the rule that raised it, like duplicate-key
+	Rule      string // stable ID of that rule, like ED006
 	Message   string
 	KeyName   string
+	Fix       *Fix // suggested edit, nil when there's no obvious one
+	Span           // where on LineNum it is, zero when it's about the whole line or file
 }
 
-// this is gonna be for the final report
+// Span is a stretch of one line. Columns are 1-based and count bytes, offsets count bytes from the
+// start of the file, both ends are exclusive. A zero Column means the position isn't known
+type Span struct {
+	Column    int
+	EndColumn int
+	Offset    int
+	EndOffset int
+}
+
+// Fix replaces lines Line to EndLine of the file with Text, or removes them when Delete is set
+type Fix struct {
+	Description string
+	Line        int
+	EndLine     int
+	Text        string // may hold several lines, without the final newline
+	Delete      bool
+}
+
+// this is gonna be for the final report, one per checked file
 type Report struct {
+	File     string
+	Source   string // the file's contents for showing issues in context, read from File when empty
 	Errors   []Issue
 	Warnings []Issue
 }
 
 // refers to environment variable
 type EnvVar struct {
-	Value   string
-	LineNum int
+	Value     string
+	LineNum   int
+	EndLine   int      // same as LineNum unless the value spans several lines
+	Quote     byte     // '"' or '\'' when the value was quoted, single-quoted values are never expanded
+	Ignore    []string // rule IDs silenced by envdoc: comments on the key's lines
+	KeySpan   Span     // where the key and the value are written on LineNum
+	ValueSpan Span
 }
 type EnvVarMap map[string]EnvVar
 
@@ -33,15 +64,27 @@ type SchemaItem struct {
 	Type      string `yaml:"type"`
 	Required  bool   `yaml:"required"`
 	Sensitive bool   `yaml:"sensitive"`
+
+	// optional, written by hand and kept when the schema is regenerated
+	Description string   `json:",omitempty" yaml:"description,omitempty"` // shown when hovering the key in an editor
+	Enum        []string `json:",omitempty" yaml:"enum,omitempty"`
+	Min         *float64 `json:",omitempty" yaml:"min,omitempty"`
+	Max         *float64 `json:",omitempty" yaml:"max,omitempty"`
+	Pattern     string   `json:",omitempty" yaml:"pattern,omitempty"`
+	MinLength   *int     `json:",omitempty" yaml:"minLength,omitempty"`
+	MaxLength   *int     `json:",omitempty" yaml:"maxLength,omitempty"`
 }
 type Schema map[string]SchemaItem
 
 type Diff struct {
-	DiffType string
-	Message  string
-	KeyName  string //the relevant key that might be missing ?? not sure
-	Value1   string //this is incase there is a different value between two files with the same key
-	Value2   string
+	DiffType  string `json:"type" yaml:"type"` // "missing key" or "difference in value"
+	Message   string `json:"message" yaml:"message"`
+	KeyName   string `json:"key" yaml:"key"`
+	Value1    string `json:"value1" yaml:"value1"` //this is incase there is a different value between two files with the same key
+	Value2    string `json:"value2" yaml:"value2"`
+	Line1     int    `json:"line1,omitempty" yaml:"line1,omitempty"` // 0 when the first file doesn't have the key
+	Line2     int    `json:"line2,omitempty" yaml:"line2,omitempty"`
+	Sensitive bool   `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
 }
 
 type DiffMap []Diff